@@ -2,6 +2,7 @@ package qtx
 
 import (
 	"context"
+	"github.com/qbixus/qtx-go/internal"
 )
 
 func WithTransaction(ctx context.Context, tx Transaction) context.Context {
@@ -15,3 +16,47 @@ func CurrentTransaction(ctx context.Context) Transaction {
 	}
 	return tx
 }
+
+// AbortCause возвращает причину отмены транзакции из контекста, переданного участнику транзакции в
+// [EnlistmentNotification.Rollback]: ошибку, соответствующую ErrTxAborted и дополненную причиной отмены, если она
+// известна. Для прочих контекстов возвращает nil.
+func AbortCause(ctx context.Context) error {
+	cause, ok := ctx.Value(contextKey[error]{}).(error)
+	if !ok {
+		return nil
+	}
+	return cause
+}
+
+// WithCancelOnAbort возвращает производный по отношению к ctx контекст, который отменяется вскоре после отмены
+// транзакции tx, а для CommittableTransaction и ее зависимых клонов - как только транзакция обречена на отмену, в т.ч.
+// помечена как подлежащая отмене (см. [Transaction.SetRollbackOnly]). Причиной отмены контекста (см. context.Cause)
+// является ошибка результата транзакции, соответствующая ErrTxAborted.
+// Отмена контекста позволяет прекратить длительную работу в транзакции, не дожидаясь ее завершения.
+//
+// Возвращает результирующий контекст и функцию его отмены, которая должна быть вызвана по завершении работы.
+func WithCancelOnAbort(ctx context.Context, tx Transaction) (context.Context, context.CancelFunc) {
+	internal.Assert(tx != nil, "#args: tx")
+	ctx, cancel := context.WithCancelCause(ctx)
+	f := func(status TransactionStatus, err error) {
+		if status == TransactionStatusAborted {
+			cancel(err)
+		}
+	}
+	var stop func() bool
+	if d, ok := tx.(interface {
+		afterDoom(f func(status TransactionStatus, err error)) (stop func() bool)
+	}); ok {
+		stop = d.afterDoom(f)
+	} else {
+		stop = tx.AfterFunc(f)
+	}
+	return ctx, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+func withAbortCause(ctx context.Context, cause error) context.Context {
+	return context.WithValue(ctx, contextKey[error]{}, cause)
+}
@@ -2,210 +2,697 @@ package qtx
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/qbixus/qtx-go/internal"
+	"maps"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // CommittableTransaction - локальная транзакция [Transaction], изменения в которой могут быть зафиксированы.
 type CommittableTransaction struct {
-	mu     sync.Mutex
-	status txStatus
-	tod    SinglePhaseNotification  // The Only Durable TRM.
-	vrms   []EnlistmentNotification // Volatile TRM-s.
+	opts TransactionOptions
+
+	mu      sync.Mutex
+	id      uint64
+	created time.Time
+	status  txStatus
+	trms    []participant // TRM-s в порядке присоединения.
+	drmsNo  int           // Количество присоединений долгосрочных TRM-s.
+	vrmsNo  int           // Количество присоединений не долгосрочных TRM-s.
+	roNo    int           // Количество TRM-s, проголосовавших "только чтение".
+	cause   error         // Причина отмены.
+	doomed  bool          // Транзакция помечена как подлежащая отмене, см. SetRollbackOnly.
+	err     error         // Ошибка результата завершенной транзакции.
+	timer   *time.Timer   // Таймер автоматической отмены по истечении времени жизни.
+	done    chan struct{} // Закрывается после завершения транзакции, создается при первом обращении к Done.
+
+	cancelPrep context.CancelCauseFunc // Отменяет контекст участников подготовки 2PC и SPC выполняемого Commit.
+
+	phase0Completed bool          // Фаза 0 завершена, присоединения к ней не допускаются.
+	phase2Completed bool          // Получены все ответы заключительного этапа.
+	phase2Done      chan struct{} // Закрывается при получении всех ответов заключительного этапа.
+
+	afterFuncs map[*afterFunc]struct{}
+
+	blockingClonesNo int           // Количество не завершенных клонов, блокирующих фиксацию изменений.
+	rollbackClonesNo int           // Количество не завершенных клонов, отменяющих фиксацию изменений.
+	wake             chan struct{} // Пробуждение Commit, ожидающего завершения клонов.
+
+	promotingNo int           // Количество выполняемых продвижений.
+	promoted    chan struct{} // Закрывается по завершении выполняемых продвижений, см. awaitPromotions.
 
 	// Для исключения конкурирующих друг с другом Commit и Rollback, в дополнение к mu
 	ctlMu sync.Mutex
 }
 
+// NewCommittableTransaction создает транзакцию с параметрами opts.
+// Нулевое значение CommittableTransaction равнозначно транзакции, созданной с нулевым значением opts.
+func NewCommittableTransaction(opts TransactionOptions) *CommittableTransaction {
+	opts.Labels = maps.Clone(opts.Labels)
+	tx := &CommittableTransaction{opts: opts}
+	tx.init()
+	if opts.Timeout > 0 {
+		tx.timer = time.AfterFunc(opts.Timeout, func() { _ = tx.RollbackErr(context.Background(), ErrTxTimeout) })
+	}
+	return tx
+}
+
+// TransactionInformation реализует [Transaction.TransactionInformation].
+// Для нулевого значения CommittableTransaction время создания - время первого обращения к TransactionInformation.
+func (tx *CommittableTransaction) TransactionInformation() TransactionInformation {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	tx.init()
+	status := tx.status.public()
+	if tx.doomed && status == TransactionStatusActive {
+		status = TransactionStatusAborted
+	}
+	return TransactionInformation{
+		LocalID:             tx.id,
+		Status:              status,
+		CreationTime:        tx.created,
+		DurableEnlistments:  tx.drmsNo,
+		VolatileEnlistments: tx.vrmsNo,
+		ReadOnlyEnlistments: tx.roNo,
+	}
+}
+
+// AfterFunc реализует [Transaction.AfterFunc].
+func (tx *CommittableTransaction) AfterFunc(f func(status TransactionStatus, err error)) (stop func() bool) {
+	return tx.addAfterFunc(&afterFunc{f: f})
+}
+
+// afterDoom аналогична AfterFunc, но запускает f, как только транзакция обречена на отмену (см. SetRollbackOnly и
+// вложенный Rollback), не дожидаясь ее завершения. В этом случае f получает статус TransactionStatusAborted и ошибку
+// отмены с запомненной причиной.
+func (tx *CommittableTransaction) afterDoom(f func(status TransactionStatus, err error)) (stop func() bool) {
+	return tx.addAfterFunc(&afterFunc{f: f, onDoom: true})
+}
+
+func (tx *CommittableTransaction) addAfterFunc(af *afterFunc) (stop func() bool) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.isTerminated() {
+		go af.f(tx.status.public(), tx.err)
+		return func() bool { return false }
+	}
+	if af.onDoom && (tx.doomed || tx.status == txStatusPrepareAborted) {
+		go af.f(TransactionStatusAborted, tx.abortErr())
+		return func() bool { return false }
+	}
+
+	if tx.afterFuncs == nil {
+		tx.afterFuncs = make(map[*afterFunc]struct{})
+	}
+	tx.afterFuncs[af] = struct{}{}
+
+	return func() bool {
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+
+		_, ok := tx.afterFuncs[af]
+		delete(tx.afterFuncs, af)
+		return ok
+	}
+}
+
+// Done реализует [Transaction.Done].
+func (tx *CommittableTransaction) Done() <-chan struct{} {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done == nil {
+		tx.done = make(chan struct{})
+		if tx.isTerminated() {
+			close(tx.done)
+		}
+	}
+	return tx.done
+}
+
+// Err реализует [Transaction.Err].
+func (tx *CommittableTransaction) Err() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	return tx.err
+}
+
+// DependentClone реализует [Transaction.DependentClone].
+func (tx *CommittableTransaction) DependentClone(opt DependentCloneOption) *DependentTransaction {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	clone := &DependentTransaction{Transaction: tx, tx: tx, opt: opt}
+	if tx.status == txStatusActive || tx.status == txStatusPreparing && tx.blockingClonesNo > 0 {
+		clone.tracked = true
+		switch opt {
+		case DependentCloneBlockCommitUntilComplete:
+			tx.blockingClonesNo++
+		case DependentCloneRollbackIfNotComplete:
+			tx.rollbackClonesNo++
+		default:
+			internal.Assert(false, "#args: opt")
+		}
+	}
+	return clone
+}
+
+// Options реализует [Transaction.Options].
+func (tx *CommittableTransaction) Options() TransactionOptions {
+	opts := tx.opts
+	opts.Labels = maps.Clone(opts.Labels)
+	return opts
+}
+
 // EnlistTheOnlyDurable реализует [Transaction.EnlistTheOnlyDurable].
-func (tx *CommittableTransaction) EnlistTheOnlyDurable(drm SinglePhaseNotification) error {
+func (tx *CommittableTransaction) EnlistTheOnlyDurable(drm SinglePhaseNotification, opts ...EnlistOption) (
+	*EnlistmentHandle, error,
+) {
+	options := newEnlistOptions(opts)
+
 	tx.mu.Lock()
 	defer tx.mu.Unlock()
 
-	if tx.tod != nil {
-		return ErrTxError
+	if tx.hasDurable() {
+		return nil, ErrTxError
 	}
 
-	if !(tx.status == txStatusActive || tx.isPreparing()) {
-		return ErrTxError
+	if err := tx.enlistErr(); err != nil {
+		return nil, err
 	}
-	tx.tod = drm
-	return nil
+	if err := tx.checkDependencies(participantTheOnlyDurable, options); err != nil {
+		return nil, err
+	}
+	return tx.enlist(participant{trm: drm, kind: participantTheOnlyDurable}, options), nil
+}
+
+// EnlistDurable реализует [Transaction.EnlistDurable].
+func (tx *CommittableTransaction) EnlistDurable(drm EnlistmentNotification, opts ...EnlistOption) (
+	*EnlistmentHandle, error,
+) {
+	options := newEnlistOptions(opts)
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.hasTheOnlyDurable() {
+		return nil, ErrTxError
+	}
+
+	if err := tx.enlistErr(); err != nil {
+		return nil, err
+	}
+	if err := tx.checkDependencies(participantDurable, options); err != nil {
+		return nil, err
+	}
+	if err := tx.promoteEnlisted(); err != nil {
+		return nil, err
+	}
+
+	// ... т.к. во время продвижения транзакция не заблокирована
+	if err := tx.enlistErr(); err != nil {
+		return nil, err
+	}
+	if err := tx.checkDependencies(participantDurable, options); err != nil {
+		return nil, err
+	}
+	return tx.enlist(participant{trm: drm, kind: participantDurable}, options), nil
+}
+
+// EnlistPromotable реализует [Transaction.EnlistPromotable].
+func (tx *CommittableTransaction) EnlistPromotable(psn PromotableSinglePhaseNotification, opts ...EnlistOption) (
+	*EnlistmentHandle, error,
+) {
+	options := newEnlistOptions(opts)
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.hasTheOnlyDurable() {
+		return nil, ErrTxError
+	}
+
+	if err := tx.enlistErr(); err != nil {
+		return nil, err
+	}
+	if err := tx.checkDependencies(participantPromotable, options); err != nil {
+		return nil, err
+	}
+	if !tx.hasDurable() {
+		return tx.enlist(participant{trm: promotableNotification{psn}, kind: participantPromotable}, options), nil
+	}
+	if err := tx.promoteEnlisted(); err != nil {
+		return nil, err
+	}
+	drm, err := tx.promote(psn)
+	if err != nil {
+		return nil, err
+	}
+
+	// ... т.к. во время продвижения транзакция не заблокирована
+	if err := tx.enlistErr(); err != nil {
+		return nil, err
+	}
+	if err := tx.checkDependencies(participantPromotable, options); err != nil {
+		return nil, err
+	}
+	return tx.enlist(drm, options), nil
 }
 
 // EnlistVolatile реализует [Transaction.EnlistVolatile].
-func (tx *CommittableTransaction) EnlistVolatile(vrm EnlistmentNotification) error {
+func (tx *CommittableTransaction) EnlistVolatile(vrm EnlistmentNotification, opts ...EnlistOption) (
+	*EnlistmentHandle, error,
+) {
+	options := newEnlistOptions(opts)
+
 	tx.mu.Lock()
 	defer tx.mu.Unlock()
 
-	if !(tx.status == txStatusActive || tx.isPreparing()) {
-		return ErrTxError
+	if options.phase0 && tx.phase0Completed {
+		return nil, ErrTxError
 	}
-	tx.vrms = append(tx.vrms, vrm)
-	return nil
+
+	if err := tx.enlistErr(); err != nil {
+		return nil, err
+	}
+	if err := tx.checkDependencies(participantVolatile, options); err != nil {
+		return nil, err
+	}
+	return tx.enlist(participant{trm: vrm, kind: participantVolatile, phase0: options.phase0}, options), nil
 }
 
 // Commit фиксирует изменения в транзакции.
-// Фиксация изменений выполняется поэтапно: 1) фаза подготовки 2PC; 2) фиксация SPC; 3) фаза фиксации или отмены
-// 2PC, включая отмену SPC.
+// Фиксация изменений выполняется поэтапно: 0) ожидание завершения зависимых клонов; 1) фаза подготовки 2PC, сначала
+// диспетчеров фазы 0, затем остальных диспетчеров не долговременных ресурсов, затем - долговременных; 2) фиксация
+// SPC; 3) фаза фиксации или отмены 2PC, включая отмену SPC, либо уведомление участников о неопределенном результате
+// SPC.
+// Если единственный участник транзакции - диспетчер не долговременных ресурсов, реализующий SinglePhaseNotification,
+// то фаза подготовки 2PC не выполняется, а взаимодействие с ним производится по протоколу SPC.
+// На каждом шаге участники уведомляются с учетом зависимостей между ними - см. [WithDependsOn], исполнителем
+// транзакции - см. TransactionOptions.Executor.
+// Если к началу фазы подготовки 2PC есть не завершенные клоны с опцией DependentCloneRollbackIfNotComplete, то
+// изменения отменяются с причиной ErrTxDependentIncomplete.
 // Блокируется на все время выполнения фиксации изменений за исключением обработки ответов на последнем этапе - она
-// всегда выполняется конкурентно и может завершиться уже после завершения вызова Commit.
+// всегда выполняется конкурентно и может завершиться уже после завершения вызова Commit, см. WaitCompleted.
 // Может использоваться конкурентно.
-// Допускает вложенное использование Rollback, EnlistTheOnlyDurable и EnlistVolatile на фазе подготовки 2PC.
+// Допускает вложенное использование Rollback, EnlistTheOnlyDurable, EnlistDurable, EnlistPromotable и EnlistVolatile на
+// фазе подготовки 2PC.
+//
+// Если ctx отменяется до фиксации SPC, то изменения отменяются с отказом AbortPhaseCanceled, а если во время нее - то
+// результат фиксации изменений считается не определенным.
+// Участники подготовки 2PC и SPC получают производный от ctx контекст, который отменяется, как только транзакция
+// обречена на отмену, с причиной отмены (см. context.Cause), соответствующей ErrTxAborted. Участники заключительного
+// этапа получают контекст, который сохраняет значения ctx, но не отменяется вместе с ним - см.
+// TransactionOptions.Phase2Timeout.
 //
-// Возвращает nil если изменения зафиксированы, ErrTxAborted если изменения отменены или были отменены ранее, и
-// ErrTxError если изменения были зафиксированы ранее.
+// Возвращает nil если изменения зафиксированы, [*AbortError] (соответствует ErrTxAborted) если изменения отменены,
+// ErrTxAborted если изменения были отменены ранее, ErrTxInDoubt если результат SPC не может быть определен сейчас или
+// не мог быть определен ранее, и ErrTxError если изменения были зафиксированы ранее.
 func (tx *CommittableTransaction) Commit(ctx context.Context) error {
 	tx.ctlMu.Lock()
 	defer tx.ctlMu.Unlock()
 
+	if err := tx.beginCommit(); err != nil {
+		return err
+	}
+	return tx.commit(ctx)
+}
+
+// BeginCommit начинает фиксацию изменений в транзакции аналогично Commit и возвращает ее результат, не дожидаясь ее
+// завершения: фиксация изменений выполняется конкурентно.
+// По возвращении из BeginCommit фиксация изменений уже начата - последующие Commit и Rollback, а также присоединения,
+// выполняются так же, как при их использовании во время выполнения Commit.
+// Может использоваться конкурентно.
+func (tx *CommittableTransaction) BeginCommit(ctx context.Context) *CommitResult {
+	res := &CommitResult{done: make(chan struct{})}
+
+	tx.ctlMu.Lock()
+	if err := tx.beginCommit(); err != nil {
+		tx.ctlMu.Unlock()
+		res.complete(err)
+		return res
+	}
+
+	go func() {
+		defer tx.ctlMu.Unlock()
+		res.complete(tx.commit(ctx))
+	}()
+	return res
+}
+
+// beginCommit проверяет возможность фиксации изменений и, если она возможна, переводит транзакцию в статус фиксации.
+// Выполняется под блокировкой tx.ctlMu.
+//
+// Возвращает nil если фиксация изменений начата, и результат Commit если она невозможна.
+func (tx *CommittableTransaction) beginCommit() error {
 	tx.mu.Lock()
+	defer tx.mu.Unlock()
 
 	// ... т.к. tx.ctlMu исключает конкурирующие вызовы Commit и Rollback
 	internal.Assert(tx.isTerminated() || tx.status == txStatusActive)
 
 	// Проверяем текущее состояние
 	if tx.status == txStatusAborted {
-		tx.mu.Unlock()
-		return ErrTxAborted
+		return tx.abortErr()
+	}
+	if tx.status == txStatusInDoubt {
+		return ErrTxInDoubt
 	}
 	if tx.isTerminated() {
-		tx.mu.Unlock()
 		return ErrTxError
 	}
-	// ... и возможность быстрого завершения
-	if tx.tod == nil && len(tx.vrms) == 0 {
-		tx.status = txStatusCommitted
-		tx.clear()
+
+	internal.Assert(tx.status == txStatusActive)
+
+	tx.status = txStatusPreparing
+	if tx.doomed {
+		tx.status = txStatusPrepareAborted
+	}
+	return nil
+}
+
+// commit выполняет фиксацию изменений в транзакции, начатую beginCommit.
+// Выполняется под блокировкой tx.ctlMu.
+func (tx *CommittableTransaction) commit(ctx context.Context) error {
+	// Контекст участников подготовки 2PC и SPC отменяется, как только транзакция обречена на отмену
+	prepCtx, cancelPrep := context.WithCancelCause(ctx)
+	defer cancelPrep(nil)
+
+	tx.mu.Lock()
+
+	tx.cancelPrep = cancelPrep
+
+	// Шаг 0: Ожидание завершения зависимых клонов
+
+	for tx.blockingClonesNo > 0 && tx.status == txStatusPreparing && ctx.Err() == nil {
+		wake := make(chan struct{})
+		tx.wake = wake
 		tx.mu.Unlock()
-		return nil
+		select {
+		case <-wake:
+		case <-ctx.Done():
+		}
+		tx.mu.Lock()
+	}
+	tx.awaitPromotions()
+	if tx.rollbackClonesNo > 0 && tx.status == txStatusPreparing {
+		tx.setCause(ErrTxDependentIncomplete)
+		tx.status = txStatusPrepareAborted
 	}
 
-	internal.Assert(tx.status == txStatusActive)
+	// ... и проверяем возможность быстрого завершения
+	if len(tx.trms) == 0 && tx.status == txStatusPreparing && ctx.Err() == nil {
+		tx.terminate(txStatusCommitted, nil)
+		tx.completePhase2()
+		tx.mu.Unlock()
+		return nil
+	}
 
 	// Формируем рабочий набор данных
 	var (
-		tod         = tx.tod
-		vrms        = append(make([]EnlistmentNotification, 0, len(tx.vrms)+len(tx.vrms)/2+1), tx.vrms...)
-		responses   = make(chan trmResponse, len(vrms)+1)
+		trms        = append(make([]participant, 0, len(tx.trms)+len(tx.trms)/2+1), tx.trms...)
+		spcId       = -1 // Участник, с которым взаимодействие производится по протоколу SPC.
 		shouldAbort bool
+		vetoes      []AbortVeto
+		inDoubt     bool
+		cause       error
 	)
 
+	// Учитываем возможные Rollback во время ожидания
+	if tx.status == txStatusPrepareAborted {
+		shouldAbort = true
+		vetoes = append(vetoes, AbortVeto{Enlistment: -1, Phase: AbortPhaseRollback, Cause: tx.cause})
+	}
+
 	// Шаг 1: 2PC Prepare
 
-	tx.status = txStatusPreparing
+	// Выполняем подготовку сначала не долгосрочных, затем долгосрочных ресурсов
+	for !shouldAbort {
+		// Учитываем возможную отмену ctx...
+		if ctx.Err() != nil {
+			shouldAbort = true
+			vetoes = append(vetoes, AbortVeto{Enlistment: -1, Phase: AbortPhaseCanceled, Cause: ctxCause(ctx)})
+			break
+		}
+
+		batch := nextPrepareBatch(trms, &spcId)
+		if len(batch) == 0 {
+			break
+		}
+		if !trms[batch[0]].phase0 {
+			tx.phase0Completed = true
+		}
+		for _, id := range batch {
+			tx.trms[id].prepareSent = true
+		}
 
-	// Выполняем подготовку не долгосрочных ресурсов
-	for processed := 0; !shouldAbort && processed < len(vrms); {
 		tx.mu.Unlock()
 
-		for i := processed; i < len(vrms); i++ {
-			vrms[i].Prepare(ctx, enlistment{id: i, resp: responses})
-		}
+		// ... с учетом зависимостей между участниками: участник получает Prepare только после ответов тех, от кого он
+		// зависит. Ограничение времени ожидания ответов действует на весь шаг, а не на каждый уровень зависимостей
+		roNo := 0
+		deadline := tx.responseDeadline()
+		for _, level := range dependencyLevels(trms, batch) {
+			if shouldAbort {
+				break
+			}
 
-		for ; processed < len(vrms); processed++ {
-			resp, ok := <-responses
-			internal.Assert(ok)
-			switch resp.code {
-			case trmResponseCodeDone:
-				vrms[resp.enlId] = nil
-			case trmResponseCodeAbort:
+			responses := make(chan trmResponse, len(level))
+			for _, id := range level {
+				trm, enl := trms[id].trm, enlistment{id: id, resp: responses}
+				tx.execute(func() { trm.Prepare(prepCtx, enl) })
+			}
+
+			timedOut, err := awaitResponses(ctx, responses, level, deadline, func(resp trmResponse) {
+				switch resp.code {
+				case trmResponseCodeDone:
+					trms[resp.enlId].state = trmStateDone
+					roNo++
+				case trmResponseCodeAbort:
+					shouldAbort = true
+					vetoes = append(vetoes,
+						AbortVeto{Enlistment: resp.enlId, Phase: AbortPhasePrepare, Cause: resp.cause})
+					cancelPrep(abortCause(resp.cause))
+				case trmResponseCodeCommit:
+				}
+			})
+			switch {
+			case errors.Is(err, ErrTxResponseTimeout):
 				shouldAbort = true
-			case trmResponseCodeCommit:
+				for _, id := range timedOut {
+					vetoes = append(vetoes, AbortVeto{Enlistment: id, Phase: AbortPhasePrepare, Cause: err})
+				}
+				cancelPrep(abortCause(err))
+			case err != nil:
+				shouldAbort = true
+				vetoes = append(vetoes, AbortVeto{Enlistment: -1, Phase: AbortPhaseCanceled, Cause: err})
 			}
 		}
 
 		tx.mu.Lock()
 
-		// Учитываем возможные вложенные присоединения...
-		if len(tx.vrms) > len(vrms) {
-			vrms = append(vrms, tx.vrms[len(vrms):]...)
-			close(responses)
-			responses = make(chan trmResponse, len(vrms)+1)
+		tx.roNo += roNo
+
+		// Учитываем возможные вложенные присоединения, продвижения и зависимости...
+		tx.awaitPromotions()
+		for i := range trms {
+			switch {
+			case trms[i].state == trmStateActive:
+				trms[i] = tx.trms[i]
+			case i == spcId && trms[i].kind != tx.trms[i].kind:
+				// ... в т.ч. продвижение участника SPC: он становится участником 2PC, а участник SPC выбирается заново
+				trms[i] = tx.trms[i]
+				spcId = -1
+			}
+			trms[i].deps = tx.trms[i].deps
 		}
-		tod = tx.tod
+		trms = append(trms, tx.trms[len(trms):]...)
 
 		// Учитываем возможные вложенные Rollback...
 		if tx.status == txStatusPrepareAborted {
 			shouldAbort = true
+			vetoes = append(vetoes, AbortVeto{Enlistment: -1, Phase: AbortPhaseRollback, Cause: tx.cause})
 		}
 	}
 
-	// Шаг : SPC Commit
+	// Шаг 2: SPC Commit
 
 	tx.status = txStatusFinalizing
 
-	// Выполняем SPC Commit для TOD, если применимо
-	if tod != nil && !shouldAbort {
+	// Выполняем SPC Commit для TOD или последнего ресурса, если применимо и участник SPC не является участником
+	// "только чтение"
+	if spcId >= 0 && !shouldAbort {
 		tx.mu.Unlock()
 
-		tod.SinglePhaseCommit(ctx, enlistment{id: -1, resp: responses})
+		readOnly := isReadOnly(trms[spcId].trm)
+		if !readOnly {
+			responses := make(chan trmResponse, 1)
+			spn := trms[spcId].trm.(SinglePhaseNotification)
+			spn.SinglePhaseCommit(prepCtx, enlistment{id: spcId, resp: responses})
 
-		resp, ok := <-responses
-		internal.Assert(ok)
-		if resp.code != trmResponseCodeCommit {
-			shouldAbort = true
+			_, err := awaitResponses(ctx, responses, []int{spcId}, tx.responseDeadline(), func(resp trmResponse) {
+				switch resp.code {
+				case trmResponseCodeCommit:
+					trms[spcId].state = trmStateDone
+				case trmResponseCodeInDoubt:
+					trms[spcId].state = trmStateDone
+					inDoubt, cause = true, resp.cause
+				default:
+					shouldAbort = true
+					vetoes = append(vetoes,
+						AbortVeto{Enlistment: spcId, Phase: AbortPhaseSinglePhaseCommit, Cause: resp.cause})
+				}
+			})
+			if err != nil {
+				trms[spcId].state = trmStateDone
+				inDoubt, cause = true, err
+			}
 		}
 
 		tx.mu.Lock()
+
+		if readOnly {
+			trms[spcId].state = trmStateDone
+			tx.roNo++
+		}
 	}
 
-	//	Шаг 3: 2PC Rollback/Commit + SPC Rollback
+	//	Шаг 3: 2PC Rollback/Commit/InDoubt + SPC Rollback
 
 	// Фиксируем результирующий статус транзакции
-	if shouldAbort {
-		tx.status = txStatusAborted
-	} else {
-		tx.status = txStatusCommitted
+	var (
+		status txStatus
+		err    error
+	)
+	switch {
+	case shouldAbort:
+		for _, veto := range vetoes {
+			tx.setCause(veto.Cause)
+		}
+		status, err = txStatusAborted, &AbortError{Vetoes: vetoes}
+	case inDoubt:
+		status, err = txStatusInDoubt, ErrTxInDoubt
+		if cause != nil {
+			err = fmt.Errorf("%w: %w", ErrTxInDoubt, cause)
+		}
+	default:
+		status = txStatusCommitted
 	}
 
 	// Высвобождаем накопленные ресурсы - все необходимое есть в рабочем наборе данных
-	tx.clear()
+	tx.terminate(status, err)
 
 	tx.mu.Unlock()
 
-	// Инициируем необходимые Commit/Rollback
-	pendingRespsNo := 0
-	if tod != nil && shouldAbort {
-		tod.Rollback(ctx, enlistment{id: -1, resp: responses})
-		pendingRespsNo++
-	}
-	for i, vrm := range vrms {
-		if vrm == nil {
+	// Инициируем необходимые Commit/Rollback/InDoubt
+	p2Ctx, cancelP2 := tx.phase2Context(ctx)
+	rbCtx := withAbortCause(p2Ctx, err)
+	var ids []int
+	for _, i := range phase2Order(trms) {
+		if trms[i].state != trmStateDone {
 			//	"Done" присоединения игнорируем
-			continue
+			ids = append(ids, i)
 		}
-		if shouldAbort {
-			vrm.Rollback(ctx, enlistment{id: i, resp: responses})
-		} else {
-			vrm.Commit(ctx, enlistment{id: i, resp: responses})
-		}
-		pendingRespsNo++
 	}
-
-	// Запускаем конкурентную фоновую обработку ответов
-	go func() {
-		for range pendingRespsNo {
-			_, ok := <-responses
-			internal.Assert(ok)
+	tx.notifyPhase2(p2Ctx, cancelP2, dependencyLevels(trms, ids), func(i int, enl enlistment) {
+		switch {
+		case shouldAbort:
+			trms[i].trm.Rollback(rbCtx, enl)
+		case inDoubt:
+			trms[i].trm.InDoubt(p2Ctx, enl)
+		default:
+			trms[i].trm.Commit(p2Ctx, enl)
 		}
-		close(responses)
-	}()
+	})
 
 	// Завершаем вызов
 
-	if shouldAbort {
-		return ErrTxAborted
+	return err
+}
+
+// CommitResult - результат фиксации изменений, начатой [CommittableTransaction.BeginCommit].
+type CommitResult struct {
+	done chan struct{}
+	err  error
+}
+
+// Done возвращает канал, который закрывается после завершения фиксации изменений.
+func (r *CommitResult) Done() <-chan struct{} {
+	return r.done
+}
+
+// Err возвращает nil если фиксация изменений еще не завершена, и результат, который вернул бы Commit, если завершена.
+func (r *CommitResult) Err() error {
+	select {
+	case <-r.done:
+		return r.err
+	default:
+		return nil
+	}
+}
+
+func (r *CommitResult) complete(err error) {
+	r.err = err
+	close(r.done)
+}
+
+// EnlistmentHandle - присоединение диспетчера к транзакции, возвращаемое методами присоединения [Transaction].
+type EnlistmentHandle struct {
+	tx *CommittableTransaction
+}
+
+// Unenlist отсоединяет диспетчер от транзакции. Отсоединенный диспетчер не получает никаких уведомлений, а
+// идентификаторы присоединений (см. [AbortVeto]) последующих участников уменьшаются.
+// Может использоваться конкурентно.
+//
+// Возвращает nil если диспетчер был отсоединен, ErrInvalidOperation если он был отсоединен ранее, ErrTxAborted если
+// транзакция отменена, и ErrTxError если фиксация изменений уже начата или завершена.
+func (h *EnlistmentHandle) Unenlist() error {
+	return h.tx.unenlist(h)
+}
+
+// WaitCompleted ожидает завершения транзакции и получения ответов (Enlistment.Done) всех участников на
+// заключительном этапе Commit или Rollback.
+// Может использоваться конкурентно.
+//
+// Возвращает nil если ответы получены и ctx.Err() если ctx был отменен раньше.
+func (tx *CommittableTransaction) WaitCompleted(ctx context.Context) error {
+	tx.mu.Lock()
+	if tx.phase2Done == nil {
+		tx.phase2Done = make(chan struct{})
+		if tx.phase2Completed {
+			close(tx.phase2Done)
+		}
+	}
+	phase2Done := tx.phase2Done
+	tx.mu.Unlock()
+
+	select {
+	case <-phase2Done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return nil
 }
 
 // Rollback реализует [Transaction.Rollback].
 func (tx *CommittableTransaction) Rollback(ctx context.Context) error {
+	return tx.RollbackErr(ctx, nil)
+}
+
+// RollbackErr реализует [Transaction.RollbackErr].
+func (tx *CommittableTransaction) RollbackErr(ctx context.Context, cause error) error {
 	// Отрабатываем случай вложенного (и неотличимого конкурентного) вызова во время 2PC Prepare
 	tx.mu.Lock()
 	if tx.isPreparing() {
-		tx.status = txStatusPrepareAborted
+		tx.abortPreparing(cause)
 		tx.mu.Unlock()
 		return nil
 	}
@@ -215,6 +702,7 @@ func (tx *CommittableTransaction) Rollback(ctx context.Context) error {
 	defer tx.ctlMu.Unlock()
 
 	tx.mu.Lock()
+	tx.awaitPromotions()
 
 	// ... т.к. tx.ctlMu исключает конкурирующие вызовы Commit и Rollback
 	internal.Assert(tx.isTerminated() || tx.status == txStatusActive)
@@ -222,72 +710,659 @@ func (tx *CommittableTransaction) Rollback(ctx context.Context) error {
 	// Проверяем текущее состояние
 	if tx.status == txStatusAborted {
 		tx.mu.Unlock()
-		return ErrTxAborted
+		return tx.abortErr()
+	}
+	if tx.status == txStatusInDoubt {
+		tx.mu.Unlock()
+		return ErrTxInDoubt
 	}
 	if tx.isTerminated() {
 		tx.mu.Unlock()
 		return ErrTxError
 	}
 	// ... и возможность быстрого завершения
-	if tx.tod == nil && len(tx.vrms) == 0 {
-		tx.status = txStatusAborted
+	if len(tx.trms) == 0 {
+		tx.setCause(cause)
+		tx.terminate(txStatusAborted, tx.abortErr())
+		tx.completePhase2()
 		tx.mu.Unlock()
 		return nil
 	}
 
 	// Формируем рабочий набор данных
+	tx.setCause(cause)
+	p2Ctx, cancelP2 := tx.phase2Context(ctx)
 	var (
-		tod  = tx.tod
-		vrms = tx.vrms
+		trms  = tx.trms
+		rbCtx = withAbortCause(p2Ctx, tx.abortErr())
 	)
 
 	// Единственный шаг: 2PC/SPC Rollback
 
-	// Фиксируем результирующий статус транзакции
-	tx.status = txStatusAborted
-
-	// Высвобождаем накопленные ресурсы - все необходимое есть в рабочем наборе данных
-	tx.clear()
+	// Фиксируем результирующий статус транзакции и высвобождаем накопленные ресурсы - все необходимое есть в рабочем
+	// наборе данных
+	tx.terminate(txStatusAborted, tx.abortErr())
 
 	tx.mu.Unlock()
 
 	// Инициируем необходимые Rollback
-	responses := make(chan trmResponse, len(vrms)+1)
-	pendingRespsNo := 0
-	if tod != nil {
-		tod.Rollback(ctx, enlistment{id: -1, resp: responses})
-		pendingRespsNo++
+	tx.notifyPhase2(p2Ctx, cancelP2, dependencyLevels(trms, phase2Order(trms)), func(i int, enl enlistment) {
+		trms[i].trm.Rollback(rbCtx, enl)
+	})
+
+	// Завершаем вызов
+
+	return nil
+}
+
+// SetRollbackOnly реализует [Transaction.SetRollbackOnly].
+func (tx *CommittableTransaction) SetRollbackOnly(cause error) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	switch {
+	case tx.status == txStatusActive:
+		tx.setCause(cause)
+		tx.doomed = true
+		tx.runDoomFuncs()
+	case tx.isPreparing():
+		tx.abortPreparing(cause)
+	case tx.status == txStatusAborted:
+	case tx.status == txStatusInDoubt:
+		return ErrTxInDoubt
+	default:
+		return ErrTxError
 	}
-	for i, vrm := range vrms {
-		vrm.Rollback(ctx, enlistment{id: i, resp: responses})
+	return nil
+}
+
+// abortPreparing отменяет изменения на фазе подготовки 2PC: запоминает причину отмены cause, а сама отмена выполняется
+// Commit.
+func (tx *CommittableTransaction) abortPreparing(cause error) {
+	tx.setCause(cause)
+	tx.status = txStatusPrepareAborted
+	tx.wakeCommit()
+	if tx.cancelPrep != nil {
+		tx.cancelPrep(tx.abortErr())
 	}
-	pendingRespsNo += len(vrms)
+	tx.runDoomFuncs()
+}
 
-	// Запускаем конкурентную фоновую обработку ответов
+// runDoomFuncs запускает функции, зарегистрированные afterDoom.
+func (tx *CommittableTransaction) runDoomFuncs() {
+	for af := range tx.afterFuncs {
+		if af.onDoom {
+			go af.f(TransactionStatusAborted, tx.abortErr())
+			delete(tx.afterFuncs, af)
+		}
+	}
+}
+
+// init инициализирует идентификатор и время создания транзакции, если они еще не инициализированы.
+func (tx *CommittableTransaction) init() {
+	if tx.id == 0 {
+		tx.id = lastTxId.Add(1)
+		tx.created = time.Now()
+	}
+}
+
+// enlist присоединяет участника p с зависимостями, указанными в options.
+func (tx *CommittableTransaction) enlist(p participant, options enlistOptions) *EnlistmentHandle {
+	p.handle = &EnlistmentHandle{tx: tx}
+	p.deps = slices.Clone(options.dependsOn)
+	tx.trms = append(tx.trms, p)
+	if p.isDurable() {
+		tx.drmsNo++
+	} else {
+		tx.vrmsNo++
+	}
+	for _, target := range options.precedes {
+		i := tx.indexOf(target)
+		tx.trms[i].deps = append(slices.Clip(tx.trms[i].deps), p.handle)
+	}
+	return p.handle
+}
+
+// unenlist реализует [EnlistmentHandle.Unenlist].
+func (tx *CommittableTransaction) unenlist(h *EnlistmentHandle) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	tx.awaitPromotions()
+
+	if tx.status == txStatusAborted {
+		return tx.abortErr()
+	}
+	if tx.status != txStatusActive {
+		return ErrTxError
+	}
+	i := tx.indexOf(h)
+	if i < 0 {
+		return ErrInvalidOperation
+	}
+
+	if tx.trms[i].isDurable() {
+		tx.drmsNo--
+	} else {
+		tx.vrmsNo--
+	}
+	tx.trms = slices.Delete(tx.trms, i, i+1)
+	return nil
+}
+
+// indexOf возвращает идентификатор участника с дескриптором h, либо -1 если такого участника нет.
+func (tx *CommittableTransaction) indexOf(h *EnlistmentHandle) int {
+	return slices.IndexFunc(tx.trms, func(p participant) bool { return p.handle == h })
+}
+
+// checkDependencies проверяет опции присоединения нового участника вида kind: он зависит от участников
+// options.dependsOn и от него зависят участники options.precedes.
+//
+// Возвращает nil если опции допустимы, ErrInvalidOperation если какой-либо из участников не присоединен к транзакции,
+// если к фазе 0 присоединяется диспетчер долгосрочных ресурсов, или если зависимость не может быть соблюдена на фазе
+// подготовки 2PC - диспетчер фазы 0 зависит от участника не фазы 0, либо от нового участника зависит участник, уже
+// получивший Prepare, и ErrTxDependencyCycle если зависимости образуют цикл.
+func (tx *CommittableTransaction) checkDependencies(kind participantKind, options enlistOptions) error {
+	if options.phase0 && kind != participantVolatile {
+		return ErrInvalidOperation
+	}
+	for _, h := range options.dependsOn {
+		if i := tx.indexOf(h); i < 0 || options.phase0 && !tx.trms[i].phase0 {
+			return ErrInvalidOperation
+		}
+	}
+	for _, h := range options.precedes {
+		if i := tx.indexOf(h); i < 0 || !options.phase0 && tx.trms[i].phase0 || tx.trms[i].prepareSent {
+			return ErrInvalidOperation
+		}
+	}
+
+	// Цикл образуется, если кто-либо из precedes достижим из dependsOn
+	visited := make(map[*EnlistmentHandle]bool)
+	stack := slices.Clone(options.dependsOn)
+	for len(stack) > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[h] {
+			continue
+		}
+		visited[h] = true
+		if slices.Contains(options.precedes, h) {
+			return ErrTxDependencyCycle
+		}
+		if i := tx.indexOf(h); i >= 0 {
+			stack = append(stack, tx.trms[i].deps...)
+		}
+	}
+	return nil
+}
+
+func (tx *CommittableTransaction) isTerminated() bool {
+	return tx.status == txStatusCommitted || tx.status == txStatusAborted || tx.status == txStatusInDoubt
+}
+
+func (tx *CommittableTransaction) isPreparing() bool {
+	return tx.status == txStatusPreparing || tx.status == txStatusPrepareAborted
+}
+
+// enlistErr возвращает nil если транзакция допускает новые присоединения, ошибку отмены если транзакция отменена или
+// помечена как подлежащая отмене, ErrTxTooManyParticipants если достигнуто наибольшее количество участников, и
+// ErrTxError в остальных случаях.
+func (tx *CommittableTransaction) enlistErr() error {
+	switch {
+	case tx.status == txStatusAborted || tx.doomed:
+		return tx.abortErr()
+	case !(tx.status == txStatusActive || tx.isPreparing()):
+		return ErrTxError
+	case tx.opts.MaxParticipants > 0 && len(tx.trms) >= tx.opts.MaxParticipants:
+		return ErrTxTooManyParticipants
+	}
+	return nil
+}
+
+func (tx *CommittableTransaction) hasDurable() bool {
+	for _, trm := range tx.trms {
+		if trm.isDurable() {
+			return true
+		}
+	}
+	return false
+}
+
+func (tx *CommittableTransaction) hasTheOnlyDurable() bool {
+	for _, trm := range tx.trms {
+		if trm.kind == participantTheOnlyDurable {
+			return true
+		}
+	}
+	return false
+}
+
+// setCause запоминает причину отмены, если она еще не запомнена.
+func (tx *CommittableTransaction) setCause(cause error) {
+	if tx.cause == nil {
+		tx.cause = cause
+	}
+}
+
+// abortErr возвращает ErrTxAborted, дополненную причиной отмены, если она есть.
+func (tx *CommittableTransaction) abortErr() error {
+	return abortCause(tx.cause)
+}
+
+// causeErr реализует [Cause].
+func (tx *CommittableTransaction) causeErr() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.status != txStatusAborted && tx.status != txStatusPrepareAborted && !tx.doomed {
+		return nil
+	}
+	if tx.cause == nil {
+		return ErrTxAborted
+	}
+	return tx.cause
+}
+
+// promoteEnlisted продвигает диспетчер, присоединенный в режиме с продвижением, если он есть.
+// Выполняется под блокировкой tx.mu, но продвижение выполняет без нее, см. promote.
+func (tx *CommittableTransaction) promoteEnlisted() error {
+	tx.awaitPromotions()
+
+	i := slices.IndexFunc(tx.trms, func(p participant) bool { return p.kind == participantPromotable })
+	if i < 0 {
+		return nil
+	}
+	h := tx.trms[i].handle
+	drm, err := tx.promote(tx.trms[i].trm.(promotableNotification).PromotableSinglePhaseNotification)
+	if err != nil {
+		return err
+	}
+
+	// ... т.к. на время продвижения отсоединения приостанавливаются
+	i = tx.indexOf(h)
+	internal.Assert(i >= 0)
+	drm.handle, drm.deps = tx.trms[i].handle, tx.trms[i].deps
+	tx.trms[i] = drm
+	return nil
+}
+
+// promote продвигает диспетчер psn и возвращает замещающего его участника 2PC.
+// Выполняется под блокировкой tx.mu, но Promote вызывает без нее. На время продвижения приостанавливаются Commit,
+// Rollback, отсоединения и другие продвижения - см. awaitPromotions.
+func (tx *CommittableTransaction) promote(psn PromotableSinglePhaseNotification) (participant, error) {
+	tx.promotingNo++
+	tx.mu.Unlock()
+
+	drm, err := psn.Promote()
+
+	tx.mu.Lock()
+	tx.promotingNo--
+	if tx.promotingNo == 0 && tx.promoted != nil {
+		close(tx.promoted)
+		tx.promoted = nil
+	}
+
+	if err != nil {
+		return participant{}, fmt.Errorf("%w: %w", ErrTxPromotion, err)
+	}
+	return participant{trm: drm, kind: participantDurable}, nil
+}
+
+// awaitPromotions ожидает завершения выполняемых продвижений, см. promote.
+// Выполняется под блокировкой tx.mu, временно ее освобождая.
+func (tx *CommittableTransaction) awaitPromotions() {
+	for tx.promotingNo > 0 {
+		if tx.promoted == nil {
+			tx.promoted = make(chan struct{})
+		}
+		promoted := tx.promoted
+		tx.mu.Unlock()
+		<-promoted
+		tx.mu.Lock()
+	}
+}
+
+// phase2Context возвращает производный по отношению к ctx контекст уведомлений заключительного этапа Commit или
+// Rollback: он сохраняет значения ctx, но не отменяется вместе с ним, а время его жизни ограничено Phase2Timeout, если
+// оно задано.
+func (tx *CommittableTransaction) phase2Context(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = context.WithoutCancel(ctx)
+	if tx.opts.Phase2Timeout > 0 {
+		return context.WithTimeout(ctx, tx.opts.Phase2Timeout)
+	}
+	return ctx, func() {}
+}
+
+// responseDeadline возвращает крайний срок ответов участников на очередном шаге фиксации изменений, либо нулевое
+// значение, если время ожидания ответов не ограничено - см. TransactionOptions.ResponseTimeout.
+func (tx *CommittableTransaction) responseDeadline() time.Time {
+	if tx.opts.ResponseTimeout > 0 {
+		return time.Now().Add(tx.opts.ResponseTimeout)
+	}
+	return time.Time{}
+}
+
+// execute выполняет уведомление участника f исполнителем транзакции.
+func (tx *CommittableTransaction) execute(f func()) {
+	if tx.opts.Executor == nil {
+		f()
+		return
+	}
+	tx.opts.Executor.Execute(f)
+}
+
+// notifyPhase2 уведомляет участников на заключительном этапе Commit или Rollback функцией notify по уровням
+// зависимостей levels: участников первого уровня - немедленно, а остальных - конкурентно, по получении ответов
+// участников предыдущего уровня, либо по истечении ctx. Ответы обрабатываются конкурентно, по завершении обработки
+// контекст уведомлений отменяется функцией cancel.
+func (tx *CommittableTransaction) notifyPhase2(
+	ctx context.Context, cancel context.CancelFunc, levels [][]int, notify func(id int, enl enlistment),
+) {
+	respsNo := 0
+	for _, level := range levels {
+		respsNo += len(level)
+	}
+	responses := make(chan trmResponse, respsNo)
+	dispatch := func(level []int) int {
+		for _, id := range level {
+			enl := enlistment{id: id, resp: responses}
+			tx.execute(func() { notify(id, enl) })
+		}
+		return len(level)
+	}
+
+	pendingRespsNo := 0
+	if len(levels) > 0 {
+		pendingRespsNo = dispatch(levels[0])
+	}
+
+	// Запускаем конкурентную фоновую обработку ответов и уведомление остальных уровней
 	go func() {
+		for _, level := range levels[min(1, len(levels)):] {
+		wait:
+			for ; pendingRespsNo > 0; pendingRespsNo-- {
+				select {
+				case _, ok := <-responses:
+					internal.Assert(ok)
+				case <-ctx.Done():
+					break wait
+				}
+			}
+			pendingRespsNo += dispatch(level)
+		}
 		for range pendingRespsNo {
 			_, ok := <-responses
 			internal.Assert(ok)
 		}
 		close(responses)
+		cancel()
+
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+		tx.completePhase2()
 	}()
+}
 
-	// Завершаем вызов
+// completePhase2 отмечает получение всех ответов на заключительном этапе Commit или Rollback.
+func (tx *CommittableTransaction) completePhase2() {
+	tx.phase2Completed = true
+	if tx.phase2Done != nil {
+		close(tx.phase2Done)
+	}
+}
 
-	return nil
+// completeClone учитывает завершение зависимого клона с опцией opt.
+func (tx *CommittableTransaction) completeClone(opt DependentCloneOption) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	switch opt {
+	case DependentCloneBlockCommitUntilComplete:
+		tx.blockingClonesNo--
+		if tx.blockingClonesNo == 0 {
+			tx.wakeCommit()
+		}
+	case DependentCloneRollbackIfNotComplete:
+		tx.rollbackClonesNo--
+	}
 }
 
-func (tx *CommittableTransaction) isTerminated() bool {
-	return tx.status == txStatusCommitted || tx.status == txStatusAborted
+// wakeCommit пробуждает Commit, ожидающий завершения зависимых клонов, если он есть.
+func (tx *CommittableTransaction) wakeCommit() {
+	if tx.wake != nil {
+		close(tx.wake)
+		tx.wake = nil
+	}
 }
 
-func (tx *CommittableTransaction) isPreparing() bool {
-	return tx.status == txStatusPreparing || tx.status == txStatusPrepareAborted
+// terminate фиксирует результирующий статус транзакции и ошибку результата, высвобождает накопленные ресурсы,
+// закрывает канал Done и запускает функции, зарегистрированные AfterFunc.
+func (tx *CommittableTransaction) terminate(status txStatus, err error) {
+	tx.status = status
+	tx.err = err
+	tx.clear()
+	if tx.done != nil {
+		close(tx.done)
+	}
+	for af := range tx.afterFuncs {
+		go af.f(status.public(), err)
+	}
+	tx.afterFuncs = nil
 }
 
 func (tx *CommittableTransaction) clear() {
-	tx.tod = nil
-	tx.vrms = nil
+	tx.trms = nil
+	tx.cancelPrep = nil
+	if tx.timer != nil {
+		tx.timer.Stop()
+	}
+}
+
+// nextPrepareBatch возвращает идентификаторы участников для очередного шага 2PC Prepare: всех еще не подготовленных
+// диспетчеров фазы 0, а при их отсутствии - остальных диспетчеров не долговременных ресурсов, а при их отсутствии -
+// долговременных. Диспетчеры не долговременных ресурсов, зависящие от еще не подготовленных диспетчеров долговременных
+// ресурсов, готовятся вместе с ними.
+// При первом обращении к диспетчерам долговременных ресурсов выбирает участника SPC: TOD, либо, при его отсутствии,
+// последний из диспетчеров, реализующих SinglePhaseNotification. Если единственный участник транзакции - диспетчер не
+// долговременных ресурсов (не фазы 0), реализующий SinglePhaseNotification, то участником SPC выбирается он.
+// Выбранный участник в подготовке не участвует.
+func nextPrepareBatch(trms []participant, spcId *int) []int {
+	if len(trms) == 1 && trms[0].state == trmStateActive && trms[0].kind == participantVolatile && !trms[0].phase0 {
+		if _, ok := trms[0].trm.(SinglePhaseNotification); ok {
+			*spcId = 0
+			trms[0].state = trmStateSinglePhase
+			return nil
+		}
+	}
+
+	var batch []int
+	for i := range trms {
+		if trms[i].state == trmStateActive && trms[i].phase0 {
+			batch = append(batch, i)
+		}
+	}
+	if len(batch) == 0 {
+		for i := range trms {
+			if trms[i].state == trmStateActive && !trms[i].isDurable() && !dependsOnDurable(trms, i) {
+				batch = append(batch, i)
+			}
+		}
+	}
+	if len(batch) == 0 && *spcId < 0 {
+		for i := range trms {
+			if trms[i].state != trmStateActive || !trms[i].isDurable() {
+				continue
+			}
+			if _, ok := trms[i].trm.(SinglePhaseNotification); ok {
+				*spcId = i
+			}
+		}
+		if *spcId >= 0 {
+			trms[*spcId].state = trmStateSinglePhase
+		}
+	}
+	if len(batch) == 0 {
+		for i := range trms {
+			if trms[i].state == trmStateActive {
+				batch = append(batch, i)
+			}
+		}
+	}
+	for _, i := range batch {
+		trms[i].state = trmStatePrepared
+	}
+	return batch
+}
+
+// dependsOnDurable возвращает true если участник id зависит, в т.ч. косвенно, от еще не подготовленного диспетчера
+// долговременных ресурсов.
+func dependsOnDurable(trms []participant, id int) bool {
+	visited := make(map[int]bool)
+	stack := []int{id}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, dep := range trms[i].deps {
+			j := slices.IndexFunc(trms, func(p participant) bool { return p.handle == dep })
+			if j < 0 || visited[j] {
+				continue
+			}
+			if trms[j].state == trmStateActive && trms[j].isDurable() {
+				return true
+			}
+			visited[j] = true
+			stack = append(stack, j)
+		}
+	}
+	return false
+}
+
+// awaitResponses ожидает ответы участников ids, но не дольше deadline, если он задан, и не дольше отмены ctx, и
+// передает каждый полученный ответ в handle. Канал responses закрывается только если ответили все участники -
+// опоздавшие ответы остаются в его буфере.
+//
+// Возвращает идентификаторы не ответивших участников и причину прекращения ожидания: ErrTxResponseTimeout, либо
+// ошибку отмены ctx.
+func awaitResponses(
+	ctx context.Context, responses chan trmResponse, ids []int, deadline time.Time, handle func(trmResponse),
+) ([]int, error) {
+	var expired <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	pending := slices.Clone(ids)
+	for len(pending) > 0 {
+		select {
+		case resp, ok := <-responses:
+			internal.Assert(ok)
+			pending = slices.DeleteFunc(pending, func(id int) bool { return id == resp.enlId })
+			handle(resp)
+		case <-expired:
+			return pending, ErrTxResponseTimeout
+		case <-ctx.Done():
+			return pending, ctxCause(ctx)
+		}
+	}
+	close(responses)
+	return nil, nil
+}
+
+// abortCause возвращает ErrTxAborted, дополненную причиной отмены cause, если она есть.
+func abortCause(cause error) error {
+	if cause == nil {
+		return ErrTxAborted
+	}
+	if errors.Is(cause, ErrTxAborted) {
+		return cause
+	}
+	return fmt.Errorf("%w: %w", ErrTxAborted, cause)
+}
+
+// ctxCause возвращает ошибку отмены ctx, дополненную причиной отмены, если она отличается от ошибки отмены.
+func ctxCause(ctx context.Context) error {
+	err, cause := ctx.Err(), context.Cause(ctx)
+	if cause == nil || errors.Is(cause, err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", err, cause)
+}
+
+// isReadOnly возвращает true если участник trm реализует [ReadOnlyNotification] и сообщает, что не изменял ресурсы.
+func isReadOnly(trm EnlistmentNotification) bool {
+	var ro ReadOnlyNotification
+	switch trm := trm.(type) {
+	case promotableNotification:
+		ro, _ = trm.PromotableSinglePhaseNotification.(ReadOnlyNotification)
+	case ReadOnlyNotification:
+		ro = trm
+	}
+	return ro != nil && ro.IsReadOnly()
+}
+
+// dependencyLevels разбивает участников ids на уровни зависимостей: участники каждого уровня зависят только от
+// участников предыдущих уровней. Зависимости от участников, не входящих в ids, не учитываются. Порядок участников
+// внутри уровня соответствует их порядку в ids.
+func dependencyLevels(trms []participant, ids []int) [][]int {
+	byHandle := make(map[*EnlistmentHandle]int, len(ids))
+	for _, id := range ids {
+		byHandle[trms[id].handle] = id
+	}
+
+	levelOf := make(map[int]int, len(ids))
+	var level func(id int) int
+	level = func(id int) int {
+		if l, ok := levelOf[id]; ok {
+			return l
+		}
+		l := 0
+		for _, dep := range trms[id].deps {
+			if depId, ok := byHandle[dep]; ok {
+				l = max(l, level(depId)+1)
+			}
+		}
+		levelOf[id] = l
+		return l
+	}
+
+	var levels [][]int
+	for _, id := range ids {
+		l := level(id)
+		for len(levels) <= l {
+			levels = append(levels, nil)
+		}
+		levels[l] = append(levels[l], id)
+	}
+	return levels
+}
+
+// phase2Order возвращает идентификаторы участников в порядке выполнения фазы 2PC Commit/Rollback: сначала
+// диспетчеры долговременных ресурсов, затем - не долговременных.
+func phase2Order(trms []participant) []int {
+	order := make([]int, 0, len(trms))
+	for i := range trms {
+		if trms[i].isDurable() {
+			order = append(order, i)
+		}
+	}
+	for i := range trms {
+		if !trms[i].isDurable() {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// ---
+
+var lastTxId atomic.Uint64
+
+type afterFunc struct {
+	f      func(status TransactionStatus, err error)
+	onDoom bool // Запускается, как только транзакция обречена на отмену, см. afterDoom.
 }
 
 // ---
@@ -301,4 +1376,76 @@ const (
 	txStatusFinalizing
 	txStatusCommitted
 	txStatusAborted
+	txStatusInDoubt
+)
+
+func (s txStatus) public() TransactionStatus {
+	switch s {
+	case txStatusActive:
+		return TransactionStatusActive
+	case txStatusPreparing, txStatusFinalizing:
+		return TransactionStatusPreparing
+	case txStatusCommitted:
+		return TransactionStatusCommitted
+	case txStatusPrepareAborted, txStatusAborted:
+		return TransactionStatusAborted
+	case txStatusInDoubt:
+		return TransactionStatusInDoubt
+	}
+	internal.Assert(false)
+	return 0
+}
+
+// ---
+
+// participant - присоединенный к транзакции TRM.
+type participant struct {
+	trm    EnlistmentNotification
+	kind   participantKind
+	phase0 bool                // Диспетчер фазы 0.
+	handle *EnlistmentHandle   // Идентифицирует участника при отсоединении.
+	deps   []*EnlistmentHandle // Участники, от которых зависит участник.
+	state  trmState            // Используется только в рабочем наборе данных Commit.
+
+	prepareSent bool // Участник уже получил Prepare. Используется только в tx.trms.
+}
+
+func (p participant) isDurable() bool {
+	return p.kind != participantVolatile
+}
+
+type participantKind int
+
+const (
+	participantVolatile participantKind = iota
+	participantDurable
+	participantTheOnlyDurable
+	participantPromotable
+)
+
+// promotableNotification приводит не продвинутый диспетчер к SinglePhaseNotification. Т.к. до продвижения
+// взаимодействие с ним производится только по протоколу SPC, то методы 2PC не используются.
+type promotableNotification struct {
+	PromotableSinglePhaseNotification
+}
+
+func (p promotableNotification) Prepare(context.Context, PreparingEnlistment) {
+	internal.Assert(false)
+}
+
+func (p promotableNotification) Commit(context.Context, Enlistment) {
+	internal.Assert(false)
+}
+
+func (p promotableNotification) InDoubt(context.Context, Enlistment) {
+	internal.Assert(false)
+}
+
+type trmState int
+
+const (
+	trmStateActive trmState = iota
+	trmStatePrepared
+	trmStateSinglePhase
+	trmStateDone
 )
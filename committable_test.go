@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"testing/synctest"
 	"time"
@@ -46,13 +47,13 @@ func TestCommittableTransaction_Rollback(t *testing.T) {
 		drm := NewMockSinglePhaseNotification(t)
 
 		target := CommittableTransaction{}
-		if err := target.EnlistVolatile(vrm1); err != nil {
+		if _, err := target.EnlistVolatile(vrm1); err != nil {
 			t.Fatal(err)
 		}
-		if err := target.EnlistVolatile(vrm2); err != nil {
+		if _, err := target.EnlistVolatile(vrm2); err != nil {
 			t.Fatal(err)
 		}
-		if err := target.EnlistTheOnlyDurable(drm); err != nil {
+		if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
 			t.Fatal(err)
 		}
 
@@ -83,7 +84,7 @@ func TestCommittableTransaction_Rollback(t *testing.T) {
 			vrm := NewMockEnlistmentNotification(t)
 
 			target := CommittableTransaction{}
-			if err := target.EnlistVolatile(vrm); err != nil {
+			if _, err := target.EnlistVolatile(vrm); err != nil {
 				t.Fatal(err)
 			}
 
@@ -116,459 +117,2888 @@ func TestCommittableTransaction_Rollback(t *testing.T) {
 	})
 }
 
+func TestCommittableTransaction_RollbackErr(t *testing.T) {
+	t.Run("Запоминает причину отмены", func(t *testing.T) {
+		assert_ := assert.New(t)
+		theErr := errors.New("#THE_ERR")
+		target := CommittableTransaction{}
+
+		// Act
+		actErr := target.RollbackErr(t.Context(), theErr)
+
+		assert_.NoError(actErr)
+		assert_.Equal(theErr, Cause(&target))
+		commErr := target.Commit(t.Context())
+		assert_.ErrorIs(commErr, ErrTxAborted)
+		assert_.ErrorIs(commErr, theErr)
+	})
+
+	t.Run("Запоминает только первую причину отмены", func(t *testing.T) {
+		assert_ := assert.New(t)
+		theErr := errors.New("#THE_ERR")
+		target := CommittableTransaction{}
+		if err := target.RollbackErr(t.Context(), theErr); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		actErr := target.RollbackErr(t.Context(), errors.New("#OTHER_ERR"))
+
+		assert_.ErrorIs(actErr, ErrTxAborted)
+		assert_.ErrorIs(actErr, theErr)
+		assert_.Equal(theErr, Cause(&target))
+	})
+
+	t.Run("Может применяться на фазе подготовки", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm := NewMockEnlistmentNotification(t)
+		theErr := errors.New("#THE_ERR")
+
+		target := CommittableTransaction{}
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+
+		var rbErr error
+		wg.Add(1)
+		mock.InOrder(
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) {
+					rbErr = target.RollbackErr(ctx, theErr)
+					enl.Prepared()
+				}).
+				Once(),
+			vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once(),
+		)
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.ErrorIs(actErr, ErrTxAborted)
+		assert_.ErrorIs(actErr, theErr)
+		assert_.NoError(rbErr)
+		wg.Wait()
+	})
+}
+
+func TestCommittableTransaction_SetRollbackOnly(t *testing.T) {
+	t.Run("Откладывает отмену до Commit", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			theErr := errors.New("#THE_ERR")
+			vrm := NewMockEnlistmentNotification(t)
+
+			target := NewCommittableTransaction(TransactionOptions{})
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			actErr := target.SetRollbackOnly(theErr)
+
+			synctest.Wait()
+			assert_.NoError(actErr)
+			assert_.Equal(TransactionStatusAborted, target.TransactionInformation().Status)
+			assert_.Equal(theErr, Cause(target))
+			_, enlErr := target.EnlistVolatile(NewMockEnlistmentNotification(t))
+			assert_.ErrorIs(enlErr, ErrTxAborted)
+			assert_.ErrorIs(enlErr, theErr)
+
+			vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+			commErr := target.Commit(t.Context())
+			var abortErr *AbortError
+			if assert_.ErrorAs(commErr, &abortErr) {
+				assert_.Equal([]AbortVeto{
+					{Enlistment: -1, Phase: AbortPhaseRollback, Cause: theErr},
+				}, abortErr.Vetoes)
+			}
+			assert_.NoError(target.WaitCompleted(t.Context()))
+		})
+	})
+
+	t.Run("Не допускается после фиксации", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+		if err := target.Commit(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		actErr := target.SetRollbackOnly(nil)
+
+		assert_.ErrorIs(actErr, ErrTxError)
+		assert_.NoError(Cause(target))
+	})
+}
+
+func TestAbortCause(t *testing.T) {
+	t.Run("Передает причину отмены в Rollback", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm := NewMockEnlistmentNotification(t)
+		theErr := errors.New("#THE_ERR")
+
+		target := CommittableTransaction{}
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+
+		var actCause error
+		wg.Add(1)
+		vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) {
+				defer wg.Done()
+				actCause = AbortCause(ctx)
+				enl.Done()
+			}).
+			Once()
+
+		// Act
+		if err := target.RollbackErr(t.Context(), theErr); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Wait()
+		assert_.ErrorIs(actCause, ErrTxAborted)
+		assert_.ErrorIs(actCause, theErr)
+	})
+
+	t.Run("Передает отказы участников в Rollback", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm1 := NewMockEnlistmentNotification(t)
+		vrm2 := NewMockEnlistmentNotification(t)
+		theErr := errors.New("#THE_ERR")
+
+		target := CommittableTransaction{}
+		if _, err := target.EnlistVolatile(vrm1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistVolatile(vrm2); err != nil {
+			t.Fatal(err)
+		}
+
+		var actCause error
+		wg.Add(2)
+		mock.InOrder(
+			vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once(),
+			vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.ForceRollback(theErr) }).
+				Once(),
+			vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) {
+					defer wg.Done()
+					actCause = AbortCause(ctx)
+					enl.Done()
+				}).
+				Once(),
+			vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once(),
+		)
+
+		// Act
+		commErr := target.Commit(t.Context())
+
+		wg.Wait()
+		var abortErr *AbortError
+		assert_.ErrorAs(actCause, &abortErr)
+		assert_.ErrorIs(actCause, theErr)
+		assert_.Equal(commErr, actCause)
+	})
+
+	t.Run("Возвращает nil для прочих контекстов", func(t *testing.T) {
+		assert_ := assert.New(t)
+
+		// Act
+		actCause := AbortCause(t.Context())
+
+		assert_.NoError(actCause)
+	})
+}
+
+func TestCause(t *testing.T) {
+	t.Run("Возвращает nil если транзакция не отменена", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := CommittableTransaction{}
+
+		// Act
+		actErr := Cause(&target)
+
+		assert_.NoError(actErr)
+	})
+
+	t.Run("Возвращает ErrTxAborted если причина отмены не указана", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := CommittableTransaction{}
+		if err := target.Rollback(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		actErr := Cause(&target)
+
+		assert_.Equal(ErrTxAborted, actErr)
+	})
+
+	t.Run("Возвращает причину отмены участником", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm := NewMockEnlistmentNotification(t)
+		theErr := errors.New("#THE_ERR")
+
+		target := CommittableTransaction{}
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(1)
+		mock.InOrder(
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.ForceRollback(theErr) }).
+				Once(),
+			vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once(),
+		)
+		if err := target.Commit(t.Context()); !errors.Is(err, theErr) {
+			t.Fatal(err)
+		}
+
+		// Act
+		actErr := Cause(&target)
+
+		assert_.Equal(theErr, actErr)
+		wg.Wait()
+	})
+}
+
+func TestNewCommittableTransaction(t *testing.T) {
+	t.Run("Ограничивает количество участников", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := NewCommittableTransaction(TransactionOptions{MaxParticipants: 1})
+		if _, err := target.EnlistVolatile(NewMockEnlistmentNotification(t)); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		_, actErr := target.EnlistDurable(NewMockEnlistmentNotification(t))
+
+		assert_.ErrorIs(actErr, ErrTxTooManyParticipants)
+		assert_.ErrorIs(actErr, ErrTxError)
+	})
+
+	t.Run("Изолирует параметры от изменений", func(t *testing.T) {
+		assert_ := assert.New(t)
+		labels := map[string]string{"key": "value"}
+		target := NewCommittableTransaction(TransactionOptions{Labels: labels})
+
+		// Act
+		labels["key"] = "other"
+		target.Options().Labels["key"] = "other"
+
+		assert_.Equal(map[string]string{"key": "value"}, target.Options().Labels)
+	})
+
+	t.Run("Отменяет транзакцию по истечении времени жизни", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			vrm1 := NewMockEnlistmentNotification(t)
+			vrm2 := NewMockEnlistmentNotification(t)
+
+			target := NewCommittableTransaction(TransactionOptions{Timeout: time.Second})
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
+				t.Fatal(err)
+			}
+
+			var actCause error
+			vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { actCause = AbortCause(ctx); enl.Done() }).
+				Once()
+
+			// Act
+			time.Sleep(time.Second)
+			synctest.Wait()
+
+			assert_.ErrorIs(actCause, ErrTxTimeout)
+			_, err := target.EnlistVolatile(vrm2)
+			assert_.ErrorIs(err, ErrTxTimeout)
+			commErr := target.Commit(t.Context())
+			assert_.ErrorIs(commErr, ErrTxTimeout)
+			assert_.ErrorIs(commErr, ErrTxAborted)
+			assert_.Equal(ErrTxTimeout, Cause(target))
+		})
+	})
+
+	t.Run("Отменяет транзакцию на фазе подготовки", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			vrm := NewMockEnlistmentNotification(t)
+
+			target := NewCommittableTransaction(TransactionOptions{Timeout: time.Second})
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+
+			mock.InOrder(
+				vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) {
+						go func() { time.Sleep(time.Minute); enl.Prepared() }()
+					}).
+					Once(),
+				vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.ErrorIs(actErr, ErrTxTimeout)
+			assert_.ErrorIs(actErr, ErrTxAborted)
+			synctest.Wait()
+		})
+	})
+
+	t.Run("Не отменяет завершенную транзакцию", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+
+			target := NewCommittableTransaction(TransactionOptions{Timeout: time.Second})
+			if err := target.Commit(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			time.Sleep(time.Minute)
+			synctest.Wait()
+
+			assert_.NoError(Cause(target))
+		})
+	})
+}
+
+func TestCommittableTransaction_TransactionInformation(t *testing.T) {
+	t.Run("Возвращает уникальный идентификатор", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target1 := NewCommittableTransaction(TransactionOptions{})
+		target2 := CommittableTransaction{}
+
+		// Act
+		actInfo1 := target1.TransactionInformation()
+		actInfo2 := target2.TransactionInformation()
+
+		assert_.NotZero(actInfo1.LocalID)
+		assert_.NotZero(actInfo2.LocalID)
+		assert_.NotEqual(actInfo1.LocalID, actInfo2.LocalID)
+		assert_.Equal(actInfo2, target2.TransactionInformation())
+	})
+
+	t.Run("Возвращает время создания", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			expTime := time.Now()
+			target := NewCommittableTransaction(TransactionOptions{})
+			time.Sleep(time.Second)
+
+			// Act
+			actInfo := target.TransactionInformation()
+
+			assert_.Equal(expTime, actInfo.CreationTime)
+		})
+	})
+
+	t.Run("Возвращает статус и количество участников", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm1 := NewMockEnlistmentNotification(t)
+		vrm2 := NewMockEnlistmentNotification(t)
+		drm := NewMockSinglePhaseNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistVolatile(vrm2); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+			t.Fatal(err)
+		}
+
+		var prepInfo TransactionInformation
+		wg.Add(3)
+		vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) {
+				prepInfo = target.TransactionInformation()
+				enl.ForceRollback(nil)
+			}).
+			Once()
+		vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+			Once()
+		drm.EXPECT().Rollback(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+			Once()
+		vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+			Once()
+		vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+			Once()
+
+		actInfo := target.TransactionInformation()
+		assert_.Equal(TransactionStatusActive, actInfo.Status)
+		assert_.Equal(1, actInfo.DurableEnlistments)
+		assert_.Equal(2, actInfo.VolatileEnlistments)
+
+		// Act
+		_ = target.Commit(t.Context())
+
+		assert_.Equal(TransactionStatusPreparing, prepInfo.Status)
+		actInfo = target.TransactionInformation()
+		assert_.Equal(TransactionStatusAborted, actInfo.Status)
+		assert_.Equal(1, actInfo.DurableEnlistments)
+		assert_.Equal(2, actInfo.VolatileEnlistments)
+		wg.Wait()
+	})
+}
+
+func TestCommittableTransaction_AfterFunc(t *testing.T) {
+	t.Run("Вызывает функцию после фиксации", func(t *testing.T) {
+		assert_ := assert.New(t)
+		done := make(chan struct{})
+		target := NewCommittableTransaction(TransactionOptions{})
+		var actStatus TransactionStatus
+		var actErr error
+		target.AfterFunc(func(status TransactionStatus, err error) {
+			defer close(done)
+			actStatus, actErr = status, err
+		})
+
+		// Act
+		err := target.Commit(t.Context())
+
+		<-done
+		assert_.NoError(err)
+		assert_.Equal(TransactionStatusCommitted, actStatus)
+		assert_.NoError(actErr)
+	})
+
+	t.Run("Вызывает функцию после отмены", func(t *testing.T) {
+		assert_ := assert.New(t)
+		done := make(chan struct{})
+		expCause := errors.New("cause")
+		var wg sync.WaitGroup
+		vrm := NewMockEnlistmentNotification(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+			Once()
+		var actStatus TransactionStatus
+		var actErr error
+		target.AfterFunc(func(status TransactionStatus, err error) {
+			defer close(done)
+			actStatus, actErr = status, err
+		})
+
+		// Act
+		_ = target.RollbackErr(t.Context(), expCause)
+
+		<-done
+		assert_.Equal(TransactionStatusAborted, actStatus)
+		assert_.ErrorIs(actErr, ErrTxAborted)
+		assert_.ErrorIs(actErr, expCause)
+		wg.Wait()
+	})
+
+	t.Run("Немедленно вызывает функцию для завершенной транзакции", func(t *testing.T) {
+		assert_ := assert.New(t)
+		done := make(chan struct{})
+		target := NewCommittableTransaction(TransactionOptions{})
+		if err := target.Rollback(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		var actStatus TransactionStatus
+
+		// Act
+		stop := target.AfterFunc(func(status TransactionStatus, err error) {
+			defer close(done)
+			actStatus = status
+		})
+
+		<-done
+		assert_.Equal(TransactionStatusAborted, actStatus)
+		assert_.False(stop())
+	})
+
+	t.Run("Не вызывает остановленную функцию", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			called := false
+			target := NewCommittableTransaction(TransactionOptions{})
+			stop := target.AfterFunc(func(status TransactionStatus, err error) { called = true })
+
+			// Act
+			stopped := stop()
+
+			if err := target.Commit(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+			synctest.Wait()
+			assert_.True(stopped)
+			assert_.False(stop())
+			assert_.False(called)
+		})
+	})
+}
+
+func TestCommittableTransaction_Done(t *testing.T) {
+	t.Run("Закрывает канал после завершения", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+
+		// Act
+		done := target.Done()
+
+		assert_.Equal(done, target.Done())
+		select {
+		case <-done:
+			t.Fatal("done")
+		default:
+		}
+		assert_.NoError(target.Err())
+		if err := target.Commit(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		<-done
+		assert_.NoError(target.Err())
+	})
+
+	t.Run("Возвращает закрытый канал для завершенной транзакции", func(t *testing.T) {
+		assert_ := assert.New(t)
+		expCause := errors.New("cause")
+		target := NewCommittableTransaction(TransactionOptions{})
+		if err := target.RollbackErr(t.Context(), expCause); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		done := target.Done()
+
+		<-done
+		assert_.ErrorIs(target.Err(), ErrTxAborted)
+		assert_.ErrorIs(target.Err(), expCause)
+	})
+}
+
+func TestCommittableTransaction_BeginCommit(t *testing.T) {
+	t.Run("Не ожидает завершения фиксации", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			vrm := NewMockEnlistmentNotification(t)
+			target := NewCommittableTransaction(TransactionOptions{})
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+			var enlPrepare PreparingEnlistment
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enlPrepare = enl }).
+				Once()
+			vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+
+			// Act
+			res := target.BeginCommit(t.Context())
+
+			assert_.Equal(TransactionStatusPreparing, target.TransactionInformation().Status)
+			synctest.Wait()
+			select {
+			case <-res.Done():
+				t.Fatal("done")
+			default:
+			}
+			assert_.NoError(res.Err())
+			enlPrepare.Prepared()
+			<-res.Done()
+			assert_.NoError(res.Err())
+			assert_.Equal(TransactionStatusCommitted, target.TransactionInformation().Status)
+		})
+	})
+
+	t.Run("Последующий Rollback отменяет изменения", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			expCause := errors.New("cause")
+			vrm := NewMockEnlistmentNotification(t)
+			target := NewCommittableTransaction(TransactionOptions{})
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+			var enlPrepare PreparingEnlistment
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enlPrepare = enl }).
+				Once()
+			vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+
+			// Act
+			res := target.BeginCommit(t.Context())
+
+			synctest.Wait()
+			assert_.NoError(target.RollbackErr(t.Context(), expCause))
+			enlPrepare.Prepared()
+			<-res.Done()
+			assert_.ErrorIs(res.Err(), ErrTxAborted)
+			assert_.ErrorIs(res.Err(), expCause)
+		})
+	})
+
+	t.Run("Возвращает завершенный результат для завершенной транзакции", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+		if err := target.Commit(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		res := target.BeginCommit(t.Context())
+
+		<-res.Done()
+		assert_.ErrorIs(res.Err(), ErrTxError)
+	})
+}
+
+func TestCommittableTransaction_WaitCompleted(t *testing.T) {
+	t.Run("Ожидает ответы участников после Commit", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			vrm := NewMockEnlistmentNotification(t)
+			target := NewCommittableTransaction(TransactionOptions{})
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+			var enlCommit Enlistment
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once()
+			vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enlCommit = enl }).
+				Once()
+			if err := target.Commit(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+
+			completed := false
+			go func() {
+				// Act
+				assert_.NoError(target.WaitCompleted(t.Context()))
+				completed = true
+			}()
+
+			synctest.Wait()
+			assert_.False(completed)
+			enlCommit.Done()
+			synctest.Wait()
+			assert_.True(completed)
+		})
+	})
+
+	t.Run("Ожидает ответы участников после Rollback", func(t *testing.T) {
+		assert_ := assert.New(t)
+		vrm := NewMockEnlistmentNotification(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+		vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { go enl.Done() }).
+			Once()
+		if err := target.Rollback(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		err := target.WaitCompleted(t.Context())
+
+		assert_.NoError(err)
+	})
+
+	t.Run("Не ожидает ответы для транзакции без участников", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+		if err := target.Commit(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		err := target.WaitCompleted(t.Context())
+
+		assert_.NoError(err)
+	})
+
+	t.Run("Прерывает ожидание по истечении контекста", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			target := NewCommittableTransaction(TransactionOptions{})
+			ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+			defer cancel()
+
+			// Act
+			err := target.WaitCompleted(ctx)
+
+			assert_.ErrorIs(err, context.DeadlineExceeded)
+		})
+	})
+}
+
+func TestWithCancelOnAbort(t *testing.T) {
+	t.Run("Отменяет контекст при отмене транзакции", func(t *testing.T) {
+		assert_ := assert.New(t)
+		expCause := errors.New("cause")
+		target := NewCommittableTransaction(TransactionOptions{})
+
+		// Act
+		ctx, cancel := WithCancelOnAbort(t.Context(), target)
+		defer cancel()
+
+		assert_.NoError(ctx.Err())
+		if err := target.RollbackErr(t.Context(), expCause); err != nil {
+			t.Fatal(err)
+		}
+		<-ctx.Done()
+		assert_.ErrorIs(context.Cause(ctx), ErrTxAborted)
+		assert_.ErrorIs(context.Cause(ctx), expCause)
+	})
+
+	t.Run("Отменяет контекст, как только транзакция помечена как подлежащая отмене", func(t *testing.T) {
+		assert_ := assert.New(t)
+		expCause := errors.New("cause")
+		target := NewCommittableTransaction(TransactionOptions{})
+
+		// Act
+		ctx, cancel := WithCancelOnAbort(t.Context(), target)
+		defer cancel()
+
+		if err := target.SetRollbackOnly(expCause); err != nil {
+			t.Fatal(err)
+		}
+		<-ctx.Done()
+		assert_.ErrorIs(context.Cause(ctx), ErrTxAborted)
+		assert_.ErrorIs(context.Cause(ctx), expCause)
+		assert_.NoError(target.Err())
+	})
+
+	t.Run("Отменяет контекст клона, как только транзакция помечена как подлежащая отмене", func(t *testing.T) {
+		assert_ := assert.New(t)
+		expCause := errors.New("cause")
+		target := NewCommittableTransaction(TransactionOptions{})
+		clone := target.DependentClone(DependentCloneBlockCommitUntilComplete)
+
+		// Act
+		ctx, cancel := WithCancelOnAbort(t.Context(), clone)
+		defer cancel()
+
+		if err := clone.SetRollbackOnly(expCause); err != nil {
+			t.Fatal(err)
+		}
+		<-ctx.Done()
+		assert_.ErrorIs(context.Cause(ctx), expCause)
+		assert_.NoError(clone.Complete())
+	})
+
+	t.Run("Отменяет контекст транзакции, уже помеченной как подлежащая отмене", func(t *testing.T) {
+		assert_ := assert.New(t)
+		expCause := errors.New("cause")
+		target := NewCommittableTransaction(TransactionOptions{})
+		if err := target.SetRollbackOnly(expCause); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		ctx, cancel := WithCancelOnAbort(t.Context(), target)
+		defer cancel()
+
+		<-ctx.Done()
+		assert_.ErrorIs(context.Cause(ctx), expCause)
+	})
+
+	t.Run("Не отменяет контекст при фиксации транзакции", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			target := NewCommittableTransaction(TransactionOptions{})
+
+			// Act
+			ctx, cancel := WithCancelOnAbort(t.Context(), target)
+
+			if err := target.Commit(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+			synctest.Wait()
+			assert_.NoError(ctx.Err())
+			cancel()
+			assert_.ErrorIs(context.Cause(ctx), context.Canceled)
+		})
+	})
+}
+
+func TestCommittableTransaction_EnlistDurable(t *testing.T) {
+	t.Run("Не допускает присоединение к фазе 0", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+
+		// Act
+		_, err := target.EnlistDurable(NewMockEnlistmentNotification(t), WithPhase0())
+
+		assert_.ErrorIs(err, ErrInvalidOperation)
+		assert_.Equal(0, target.TransactionInformation().DurableEnlistments)
+	})
+
+	t.Run("Выполняет подготовку с учетом зависимостей", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		drm1 := NewMockEnlistmentNotification(t)
+		drm2 := NewMockEnlistmentNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		h, err := target.EnlistDurable(drm1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistDurable(drm2, WithPrecedes(h)); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(2)
+		mock.InOrder(
+			drm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once(),
+			drm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once(),
+			drm2.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once(),
+			drm1.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once(),
+		)
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.NoError(actErr)
+		wg.Wait()
+	})
+
+	t.Run("Возвращает ошибку если присоединен TOD", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := CommittableTransaction{}
+		if _, err := target.EnlistTheOnlyDurable(NewMockSinglePhaseNotification(t)); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		_, actErr := target.EnlistDurable(NewMockEnlistmentNotification(t))
+
+		assert_.ErrorIs(actErr, ErrTxError)
+	})
+
+	t.Run("Исключает последующее присоединение TOD", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := CommittableTransaction{}
+		if _, err := target.EnlistDurable(NewMockEnlistmentNotification(t)); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		_, actErr := target.EnlistTheOnlyDurable(NewMockSinglePhaseNotification(t))
+
+		assert_.ErrorIs(actErr, ErrTxError)
+	})
+
+	t.Run("Допускает несколько диспетчеров", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := CommittableTransaction{}
+		if _, err := target.EnlistDurable(NewMockEnlistmentNotification(t)); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		_, actErr := target.EnlistDurable(NewMockEnlistmentNotification(t))
+
+		assert_.NoError(actErr)
+	})
+}
+
+func TestCommittableTransaction_EnlistPromotable(t *testing.T) {
+	t.Run("Возвращает ошибку если присоединен TOD", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := CommittableTransaction{}
+		if _, err := target.EnlistTheOnlyDurable(NewMockSinglePhaseNotification(t)); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		_, actErr := target.EnlistPromotable(NewMockPromotableSinglePhaseNotification(t))
+
+		assert_.ErrorIs(actErr, ErrTxError)
+	})
+
+	t.Run("Продвигает при присоединении другого диспетчера", func(t *testing.T) {
+		assert_ := assert.New(t)
+		psn := NewMockPromotableSinglePhaseNotification(t)
+		target := CommittableTransaction{}
+		if _, err := target.EnlistPromotable(psn); err != nil {
+			t.Fatal(err)
+		}
+
+		psn.EXPECT().Promote().Return(NewMockEnlistmentNotification(t), nil).Once()
+
+		// Act
+		_, actErr := target.EnlistDurable(NewMockEnlistmentNotification(t))
+
+		assert_.NoError(actErr)
+	})
+
+	t.Run("Продвигает при присоединении после другого диспетчера", func(t *testing.T) {
+		assert_ := assert.New(t)
+		psn := NewMockPromotableSinglePhaseNotification(t)
+		target := CommittableTransaction{}
+		if _, err := target.EnlistDurable(NewMockEnlistmentNotification(t)); err != nil {
+			t.Fatal(err)
+		}
+
+		psn.EXPECT().Promote().Return(NewMockEnlistmentNotification(t), nil).Once()
+
+		// Act
+		_, actErr := target.EnlistPromotable(psn)
+
+		assert_.NoError(actErr)
+	})
+
+	t.Run("Продвигает без блокировки транзакции", func(t *testing.T) {
+		assert_ := assert.New(t)
+		psn := NewMockPromotableSinglePhaseNotification(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistPromotable(psn); err != nil {
+			t.Fatal(err)
+		}
+
+		var info TransactionInformation
+		psn.EXPECT().Promote().
+			RunAndReturn(func() (EnlistmentNotification, error) {
+				info = target.TransactionInformation()
+				return NewMockEnlistmentNotification(t), nil
+			}).
+			Once()
+
+		// Act
+		_, actErr := target.EnlistDurable(NewMockEnlistmentNotification(t))
+
+		assert_.NoError(actErr)
+		assert_.Equal(TransactionStatusActive, info.Status)
+		assert_.Equal(2, target.TransactionInformation().DurableEnlistments)
+	})
+
+	t.Run("Rollback ожидает завершения продвижения", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			psn := NewMockPromotableSinglePhaseNotification(t)
+			promoted := NewMockEnlistmentNotification(t)
+			drm := NewMockEnlistmentNotification(t)
+			target := NewCommittableTransaction(TransactionOptions{})
+			if _, err := target.EnlistPromotable(psn); err != nil {
+				t.Fatal(err)
+			}
+
+			release := make(chan struct{})
+			psn.EXPECT().Promote().
+				RunAndReturn(func() (EnlistmentNotification, error) {
+					<-release
+					return promoted, nil
+				}).
+				Once()
+			promoted.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+			drm.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+
+			go func() {
+				_, err := target.EnlistDurable(drm)
+				assert_.NoError(err)
+			}()
+			synctest.Wait()
+
+			// Act
+			rolledBack := false
+			go func() {
+				assert_.NoError(target.Rollback(t.Context()))
+				rolledBack = true
+			}()
+			synctest.Wait()
+
+			assert_.False(rolledBack)
+			close(release)
+			synctest.Wait()
+			assert_.True(rolledBack)
+			assert_.NoError(target.WaitCompleted(t.Context()))
+		})
+	})
+
+	t.Run("Возвращает ошибку если продвижение не удалось", func(t *testing.T) {
+		assert_ := assert.New(t)
+		psn := NewMockPromotableSinglePhaseNotification(t)
+		theErr := errors.New("#THE_ERR")
+		target := CommittableTransaction{}
+		if _, err := target.EnlistPromotable(psn); err != nil {
+			t.Fatal(err)
+		}
+
+		psn.EXPECT().Promote().Return(nil, theErr).Once()
+
+		// Act
+		_, actErr := target.EnlistDurable(NewMockEnlistmentNotification(t))
+
+		assert_.ErrorIs(actErr, ErrTxPromotion)
+		assert_.ErrorIs(actErr, theErr)
+	})
+}
+
+func TestCommittableTransaction_EnlistVolatile(t *testing.T) {
+	t.Run("Не допускает присоединение к фазе 0 после ее завершения", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm := NewMockEnlistmentNotification(t)
+		phase0Vrm := NewMockEnlistmentNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+
+		var enlErr error
+		wg.Add(1)
+		vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) {
+				// Act
+				_, enlErr = target.EnlistVolatile(phase0Vrm, WithPhase0())
+				enl.Prepared()
+			}).
+			Once()
+		vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+			Once()
+
+		if err := target.Commit(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		assert_.ErrorIs(enlErr, ErrTxError)
+		wg.Wait()
+	})
+
+	t.Run("Не допускает циклические зависимости", func(t *testing.T) {
+		assert_ := assert.New(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		h1, err := target.EnlistVolatile(NewMockEnlistmentNotification(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		h2, err := target.EnlistVolatile(NewMockEnlistmentNotification(t), WithDependsOn(h1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		_, actErr := target.EnlistVolatile(NewMockEnlistmentNotification(t), WithDependsOn(h2), WithPrecedes(h1))
+
+		assert_.ErrorIs(actErr, ErrTxDependencyCycle)
+		assert_.ErrorIs(actErr, ErrInvalidOperation)
+		assert_.Equal(2, target.TransactionInformation().VolatileEnlistments)
+	})
+
+	t.Run("Не допускает зависимость диспетчера фазы 0 от участника не фазы 0", func(t *testing.T) {
+		assert_ := assert.New(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		h, err := target.EnlistVolatile(NewMockEnlistmentNotification(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		phase0H, err := target.EnlistVolatile(NewMockEnlistmentNotification(t), WithPhase0())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		_, actErr1 := target.EnlistVolatile(NewMockEnlistmentNotification(t), WithPhase0(), WithDependsOn(h))
+		_, actErr2 := target.EnlistVolatile(NewMockEnlistmentNotification(t), WithPrecedes(phase0H))
+
+		assert_.ErrorIs(actErr1, ErrInvalidOperation)
+		assert_.ErrorIs(actErr2, ErrInvalidOperation)
+		assert_.Equal(2, target.TransactionInformation().VolatileEnlistments)
+	})
+
+	t.Run("Не допускает на фазе подготовки зависимость участника, уже получившего Prepare", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm := NewMockEnlistmentNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		h, err := target.EnlistVolatile(vrm)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var enlErr error
+		wg.Add(1)
+		vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) {
+				// Act
+				_, enlErr = target.EnlistVolatile(NewMockEnlistmentNotification(t), WithPrecedes(h))
+				enl.Prepared()
+			}).
+			Once()
+		vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+			Once()
+
+		if err := target.Commit(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		assert_.ErrorIs(enlErr, ErrInvalidOperation)
+		wg.Wait()
+	})
+
+	t.Run("Не допускает зависимости от участников другой транзакции", func(t *testing.T) {
+		assert_ := assert.New(t)
+
+		other := NewCommittableTransaction(TransactionOptions{})
+		h, err := other.EnlistVolatile(NewMockEnlistmentNotification(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := NewCommittableTransaction(TransactionOptions{})
+
+		// Act
+		_, actErr := target.EnlistVolatile(NewMockEnlistmentNotification(t), WithDependsOn(h))
+
+		assert_.ErrorIs(actErr, ErrInvalidOperation)
+	})
+}
+
+func TestEnlistmentHandle_Unenlist(t *testing.T) {
+	t.Run("Отсоединяет диспетчер", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm1 := NewMockEnlistmentNotification(t)
+		vrm2 := NewMockEnlistmentNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		h, err := target.EnlistVolatile(vrm1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistVolatile(vrm2); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(1)
+		vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+			Once()
+		vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+			Once()
+
+		// Act
+		actErr := h.Unenlist()
+
+		assert_.NoError(actErr)
+		assert_.Equal(1, target.TransactionInformation().VolatileEnlistments)
+		assert_.ErrorIs(h.Unenlist(), ErrInvalidOperation)
+		assert_.NoError(target.Commit(t.Context()))
+		wg.Wait()
+	})
+
+	t.Run("Допускает присоединение после отсоединения TOD", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+		h, err := target.EnlistTheOnlyDurable(NewMockSinglePhaseNotification(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		actErr := h.Unenlist()
+
+		assert_.NoError(actErr)
+		_, err = target.EnlistDurable(NewMockEnlistmentNotification(t))
+		assert_.NoError(err)
+	})
+
+	t.Run("Не допускает отсоединение после начала фиксации", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm := NewMockEnlistmentNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		h, err := target.EnlistVolatile(vrm)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var actErr error
+		wg.Add(1)
+		vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) {
+				// Act
+				actErr = h.Unenlist()
+				enl.Prepared()
+			}).
+			Once()
+		vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+			Once()
+
+		if err := target.Commit(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		assert_.ErrorIs(actErr, ErrTxError)
+		assert_.ErrorIs(h.Unenlist(), ErrTxError)
+		wg.Wait()
+	})
+}
+
 func TestCommittableTransaction_Commit(t *testing.T) {
 	t.Run("Возвращает ошибку если транзакция уже зафиксирована", func(t *testing.T) {
 		assert_ := assert.New(t)
-		target := CommittableTransaction{}
-		if err := target.Commit(t.Context()); err != nil {
+		target := CommittableTransaction{}
+		if err := target.Commit(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.ErrorIs(actErr, ErrTxError)
+	})
+
+	t.Run("Возвращает ошибку если транзакция уже отменена", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := CommittableTransaction{}
+		if err := target.Rollback(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.ErrorIs(actErr, ErrTxAborted)
+	})
+
+	t.Run("Фиксирует все", func(t *testing.T) {
+		t.Run("Получая синхронные ответы", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm1 := NewMockEnlistmentNotification(t)
+			vrm2 := NewMockEnlistmentNotification(t)
+			drm := NewMockSinglePhaseNotification(t)
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm2); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+				t.Fatal(err)
+			}
+
+			wg.Add(2)
+			mock.InOrder(
+				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
+					Once(),
+				vrm1.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.NoError(actErr)
+			wg.Wait()
+		})
+
+		t.Run("Получая асинхронные ответы", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm1 := NewMockEnlistmentNotification(t)
+			vrm2 := NewMockEnlistmentNotification(t)
+			drm := NewMockSinglePhaseNotification(t)
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm2); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+				t.Fatal(err)
+			}
+
+			wg.Add(2)
+			mock.InOrder(
+				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { go enl.Prepared() }).
+					Once(),
+				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { go enl.Prepared() }).
+					Once(),
+				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { go enl.Committed() }).
+					Once(),
+				vrm1.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { go func() { defer wg.Done(); enl.Done() }() }).
+					Once(),
+				vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { go func() { defer wg.Done(); enl.Done() }() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.NoError(actErr)
+			wg.Wait()
+		})
+	})
+
+	t.Run("Отменяет все", func(t *testing.T) {
+		t.Run("Получая асинхронную ошибку SPC TOD", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm1 := NewMockEnlistmentNotification(t)
+			vrm2 := NewMockEnlistmentNotification(t)
+			drm := NewMockSinglePhaseNotification(t)
+			theErr := errors.New("#THE_ERR")
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm2); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+				t.Fatal(err)
+			}
+
+			wg.Add(3)
+			mock.InOrder(
+				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { go enl.Aborted(theErr) }).
+					Once(),
+				drm.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.ErrorIs(actErr, ErrTxAborted)
+			wg.Wait()
+		})
+
+		t.Run("Получая синхронную ошибку подготовки Volatile", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm1 := NewMockEnlistmentNotification(t)
+			vrm2 := NewMockEnlistmentNotification(t)
+			drm := NewMockSinglePhaseNotification(t)
+			theErr := errors.New("#THE_ERR")
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm2); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+				t.Fatal(err)
+			}
+
+			wg.Add(3)
+			mock.InOrder(
+				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.ForceRollback(theErr) }).
+					Once(),
+				drm.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.ErrorIs(actErr, ErrTxAborted)
+			wg.Wait()
+		})
+	})
+
+	t.Run("Допускает вложенные Rollback на первой фазе", func(t *testing.T) {
+		t.Run("Volatile, синхронно", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm1 := NewMockEnlistmentNotification(t)
+			vrm2 := NewMockEnlistmentNotification(t)
+			drm := NewMockSinglePhaseNotification(t)
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm2); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+				t.Fatal(err)
+			}
+
+			var rbErr error
+			wg.Add(3)
+			mock.InOrder(
+				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) {
+						rbErr = target.Rollback(ctx)
+						enl.Prepared()
+					}).
+					Once(),
+				drm.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.ErrorIs(actErr, ErrTxAborted)
+			assert_.NoError(rbErr)
+			wg.Wait()
+		})
+
+		t.Run("Volatile, асинхронно", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm1 := NewMockEnlistmentNotification(t)
+			vrm2 := NewMockEnlistmentNotification(t)
+			drm := NewMockSinglePhaseNotification(t)
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm2); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+				t.Fatal(err)
+			}
+
+			var rbErr error
+			wg.Add(3)
+			mock.InOrder(
+				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) {
+						go func() {
+							rbErr = target.Rollback(ctx)
+							enl.Prepared()
+						}()
+					}).
+					Once(),
+				drm.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.ErrorIs(actErr, ErrTxAborted)
+			assert_.NoError(rbErr)
+			wg.Wait()
+		})
+	})
+
+	t.Run("Допускает вложенные присоединения на первой фазе", func(t *testing.T) {
+		t.Run("Синхронное присоединение TOD", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm1 := NewMockEnlistmentNotification(t)
+			vrm2 := NewMockEnlistmentNotification(t)
+			drm := NewMockSinglePhaseNotification(t)
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm2); err != nil {
+				t.Fatal(err)
+			}
+
+			var enlErr error
+			wg.Add(2)
+			mock.InOrder(
+				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) {
+						_, enlErr = target.EnlistTheOnlyDurable(drm)
+						enl.Prepared()
+					}).
+					Once(),
+				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
+					Once(),
+				vrm1.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.NoError(actErr)
+			assert_.NoError(enlErr)
+			wg.Wait()
+		})
+
+		t.Run("Асинхронное присоединение TOD", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm1 := NewMockEnlistmentNotification(t)
+			vrm2 := NewMockEnlistmentNotification(t)
+			drm := NewMockSinglePhaseNotification(t)
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm2); err != nil {
+				t.Fatal(err)
+			}
+
+			var enlErr error
+			wg.Add(2)
+			mock.InOrder(
+				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) {
+						go func() {
+							_, enlErr = target.EnlistTheOnlyDurable(drm)
+							enl.Prepared()
+						}()
+					}).
+					Once(),
+				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
+					Once(),
+				vrm1.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.NoError(actErr)
+			assert_.NoError(enlErr)
+			wg.Wait()
+		})
+
+		t.Run("Синхронное присоединение Volatile", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm1 := NewMockEnlistmentNotification(t)
+			vrm2 := NewMockEnlistmentNotification(t)
+			drm := NewMockSinglePhaseNotification(t)
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+				t.Fatal(err)
+			}
+
+			var enlErr error
+			wg.Add(2)
+			mock.InOrder(
+				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) {
+						_, enlErr = target.EnlistVolatile(vrm2)
+						enl.Prepared()
+					}).
+					Once(),
+				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
+					Once(),
+				vrm1.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.NoError(actErr)
+			assert_.NoError(enlErr)
+			wg.Wait()
+		})
+	})
+
+	t.Run("Фиксирует несколько долгосрочных ресурсов", func(t *testing.T) {
+		t.Run("По 2PC", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm := NewMockEnlistmentNotification(t)
+			drm1 := NewMockEnlistmentNotification(t)
+			drm2 := NewMockEnlistmentNotification(t)
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistDurable(drm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistDurable(drm2); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+
+			wg.Add(3)
+			mock.InOrder(
+				vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				drm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				drm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				drm1.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				drm2.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.NoError(actErr)
+			wg.Wait()
+		})
+
+		t.Run("С оптимизацией последнего ресурса", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm := NewMockEnlistmentNotification(t)
+			drm1 := NewMockSinglePhaseNotification(t)
+			drm2 := NewMockEnlistmentNotification(t)
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistDurable(drm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistDurable(drm2); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+
+			wg.Add(2)
+			mock.InOrder(
+				vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				drm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				drm1.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
+					Once(),
+				drm2.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.NoError(actErr)
+			wg.Wait()
+		})
+
+		t.Run("Отменяя все при ошибке подготовки", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm := NewMockEnlistmentNotification(t)
+			drm1 := NewMockSinglePhaseNotification(t)
+			drm2 := NewMockEnlistmentNotification(t)
+			theErr := errors.New("#THE_ERR")
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistDurable(drm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistDurable(drm2); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+
+			wg.Add(3)
+			mock.InOrder(
+				vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				drm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.ForceRollback(theErr) }).
+					Once(),
+				drm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				drm2.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.ErrorIs(actErr, ErrTxAborted)
+			wg.Wait()
+		})
+	})
+
+	t.Run("Фиксирует диспетчер с продвижением", func(t *testing.T) {
+		t.Run("По SPC, если он единственный", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm := NewMockEnlistmentNotification(t)
+			psn := NewMockPromotableSinglePhaseNotification(t)
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistPromotable(psn); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+
+			wg.Add(1)
+			mock.InOrder(
+				vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				psn.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
+					Once(),
+				vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.NoError(actErr)
+			wg.Wait()
+		})
+
+		t.Run("По 2PC, если он продвинут вложенно", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm := NewMockEnlistmentNotification(t)
+			psn := NewMockPromotableSinglePhaseNotification(t)
+			promoted := NewMockEnlistmentNotification(t)
+			drm := NewMockEnlistmentNotification(t)
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistPromotable(psn); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+
+			var enlErr error
+			wg.Add(3)
+			psn.EXPECT().Promote().Return(promoted, nil).Once()
+			mock.InOrder(
+				vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) {
+						_, enlErr = target.EnlistDurable(drm)
+						enl.Prepared()
+					}).
+					Once(),
+				promoted.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				drm.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				promoted.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				drm.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			assert_.NoError(actErr)
+			assert_.NoError(enlErr)
+			wg.Wait()
+		})
+	})
+
+	t.Run("Уведомляет о неопределенном результате SPC TOD", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm1 := NewMockEnlistmentNotification(t)
+		vrm2 := NewMockEnlistmentNotification(t)
+		drm := NewMockSinglePhaseNotification(t)
+		theErr := errors.New("#THE_ERR")
+
+		target := CommittableTransaction{}
+		if _, err := target.EnlistVolatile(vrm1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistVolatile(vrm2); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(2)
+		mock.InOrder(
+			vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once(),
+			vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once(),
+			drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl SinglePhaseEnlistment) { go enl.InDoubt(theErr) }).
+				Once(),
+			vrm1.EXPECT().InDoubt(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once(),
+			vrm2.EXPECT().InDoubt(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once(),
+		)
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.ErrorIs(actErr, ErrTxInDoubt)
+		assert_.ErrorIs(actErr, theErr)
+		assert_.NotErrorIs(actErr, ErrTxAborted)
+		assert_.ErrorIs(target.Rollback(t.Context()), ErrTxInDoubt)
+		wg.Wait()
+	})
+
+	t.Run("Возвращает отказы участников", func(t *testing.T) {
+		t.Run("На фазе подготовки", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm1 := NewMockEnlistmentNotification(t)
+			vrm2 := NewMockEnlistmentNotification(t)
+			theErr1 := errors.New("#THE_ERR_1")
+			theErr2 := errors.New("#THE_ERR_2")
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistVolatile(vrm2); err != nil {
+				t.Fatal(err)
+			}
+
+			wg.Add(2)
+			mock.InOrder(
+				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.ForceRollback(theErr1) }).
+					Once(),
+				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) {
+						enl.ForceRollback(theErr2)
+						_ = target.RollbackErr(ctx, theErr2)
+					}).
+					Once(),
+				vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			var abortErr *AbortError
+			if assert_.ErrorAs(actErr, &abortErr) {
+				assert_.Equal([]AbortVeto{
+					{Enlistment: 0, Phase: AbortPhasePrepare, Cause: theErr1},
+					{Enlistment: 1, Phase: AbortPhasePrepare, Cause: theErr2},
+					{Enlistment: -1, Phase: AbortPhaseRollback, Cause: theErr2},
+				}, abortErr.Vetoes)
+			}
+			assert_.ErrorIs(actErr, ErrTxAborted)
+			assert_.ErrorIs(actErr, theErr1)
+			assert_.ErrorIs(actErr, theErr2)
+			wg.Wait()
+		})
+
+		t.Run("На этапе SPC", func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm := NewMockEnlistmentNotification(t)
+			drm := NewMockSinglePhaseNotification(t)
+			theErr := errors.New("#THE_ERR")
+
+			target := CommittableTransaction{}
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+				t.Fatal(err)
+			}
+
+			wg.Add(2)
+			mock.InOrder(
+				vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+					Once(),
+				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Aborted(theErr) }).
+					Once(),
+				drm.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+				vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+					Once(),
+			)
+
+			// Act
+			actErr := target.Commit(t.Context())
+
+			var abortErr *AbortError
+			if assert_.ErrorAs(actErr, &abortErr) {
+				assert_.Equal([]AbortVeto{
+					{Enlistment: 1, Phase: AbortPhaseSinglePhaseCommit, Cause: theErr},
+				}, abortErr.Vetoes)
+			}
+			assert_.ErrorIs(actErr, theErr)
+			wg.Wait()
+		})
+	})
+
+	t.Run("Выполняет подготовку фазы 0 до подготовки остальных участников", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm := NewMockEnlistmentNotification(t)
+		phase0Vrm1 := NewMockEnlistmentNotification(t)
+		phase0Vrm2 := NewMockEnlistmentNotification(t)
+		drm := NewMockEnlistmentNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistVolatile(phase0Vrm1, WithPhase0()); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(4)
+		mock.InOrder(
+			phase0Vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) {
+					_, err := target.EnlistVolatile(phase0Vrm2, WithPhase0())
+					assert_.NoError(err)
+					_, err = target.EnlistDurable(drm)
+					assert_.NoError(err)
+					enl.Prepared()
+				}).
+				Once(),
+			phase0Vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once(),
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once(),
+			drm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once(),
+			drm.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once(),
+		)
+		for _, vrm := range []*MockEnlistmentNotification{vrm, phase0Vrm1, phase0Vrm2} {
+			vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once()
+		}
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.NoError(actErr)
+		wg.Wait()
+	})
+
+	t.Run("Применяет SPC к единственному диспетчеру не долговременных ресурсов", func(t *testing.T) {
+		assert_ := assert.New(t)
+		vrm := NewMockSinglePhaseNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm); err != nil {
 			t.Fatal(err)
 		}
 
+		vrm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
+			Once()
+
 		// Act
 		actErr := target.Commit(t.Context())
 
-		assert_.ErrorIs(actErr, ErrTxError)
+		assert_.NoError(actErr)
+		assert_.NoError(target.WaitCompleted(t.Context()))
 	})
 
-	t.Run("Возвращает ошибку если транзакция уже отменена", func(t *testing.T) {
+	t.Run("Не применяет SPC к одному из диспетчеров не долговременных ресурсов", func(t *testing.T) {
 		assert_ := assert.New(t)
-		target := CommittableTransaction{}
-		if err := target.Rollback(t.Context()); err != nil {
+		vrm1 := NewMockSinglePhaseNotification(t)
+		vrm2 := NewMockEnlistmentNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistVolatile(vrm2); err != nil {
 			t.Fatal(err)
 		}
 
+		vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+			Once()
+		vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+			Once()
+		vrm1.EXPECT().Commit(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+			Once()
+		vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+			Once()
+
 		// Act
 		actErr := target.Commit(t.Context())
 
-		assert_.ErrorIs(actErr, ErrTxAborted)
+		assert_.NoError(actErr)
+		assert_.NoError(target.WaitCompleted(t.Context()))
 	})
 
-	t.Run("Фиксирует все", func(t *testing.T) {
-		t.Run("Получая синхронные ответы", func(t *testing.T) {
+	t.Run("Не уведомляет участников \"только чтение\" на последнем этапе", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm1 := NewMockEnlistmentNotification(t)
+		vrm2 := NewMockEnlistmentNotification(t)
+		drm := NewMockEnlistmentNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistVolatile(vrm2); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistDurable(drm); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(1)
+		vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) { enl.ReadOnly() }).
+			Once()
+		vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+			Once()
+		drm.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) { enl.ReadOnly() }).
+			Once()
+		vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+			Once()
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.NoError(actErr)
+		assert_.Equal(2, target.TransactionInformation().ReadOnlyEnlistments)
+		wg.Wait()
+	})
+
+	t.Run("Не выполняет SPC для участника \"только чтение\"", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm := NewMockEnlistmentNotification(t)
+		drm := struct {
+			*MockSinglePhaseNotification
+			*MockReadOnlyNotification
+		}{NewMockSinglePhaseNotification(t), NewMockReadOnlyNotification(t)}
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(1)
+		mock.InOrder(
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once(),
+			drm.MockReadOnlyNotification.EXPECT().IsReadOnly().Return(true).Once(),
+			vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once(),
+		)
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.NoError(actErr)
+		assert_.Equal(1, target.TransactionInformation().ReadOnlyEnlistments)
+		wg.Wait()
+	})
+
+	t.Run("Допускает обращение к транзакции из IsReadOnly", func(t *testing.T) {
+		assert_ := assert.New(t)
+		drm := struct {
+			*MockSinglePhaseNotification
+			*MockReadOnlyNotification
+		}{NewMockSinglePhaseNotification(t), NewMockReadOnlyNotification(t)}
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
+			t.Fatal(err)
+		}
+
+		var info TransactionInformation
+		drm.MockReadOnlyNotification.EXPECT().IsReadOnly().
+			RunAndReturn(func() bool {
+				info = target.TransactionInformation()
+				return true
+			}).
+			Once()
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.NoError(actErr)
+		assert_.Equal(TransactionStatusPreparing, info.Status)
+		assert_.Equal(1, target.TransactionInformation().ReadOnlyEnlistments)
+	})
+
+	t.Run("Выполняет SPC для участника, изменявшего ресурсы", func(t *testing.T) {
+		assert_ := assert.New(t)
+		psn := struct {
+			*MockPromotableSinglePhaseNotification
+			*MockReadOnlyNotification
+		}{NewMockPromotableSinglePhaseNotification(t), NewMockReadOnlyNotification(t)}
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistPromotable(psn); err != nil {
+			t.Fatal(err)
+		}
+
+		mock.InOrder(
+			psn.MockReadOnlyNotification.EXPECT().IsReadOnly().Return(false).Once(),
+			psn.MockPromotableSinglePhaseNotification.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
+				Once(),
+		)
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.NoError(actErr)
+		assert_.Zero(target.TransactionInformation().ReadOnlyEnlistments)
+	})
+
+	t.Run("Отменяет изменения, если участник не ответил вовремя на Prepare", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
 			assert_ := assert.New(t)
-			var wg sync.WaitGroup
 			vrm1 := NewMockEnlistmentNotification(t)
 			vrm2 := NewMockEnlistmentNotification(t)
-			drm := NewMockSinglePhaseNotification(t)
 
-			target := CommittableTransaction{}
-			if err := target.EnlistVolatile(vrm1); err != nil {
-				t.Fatal(err)
-			}
-			if err := target.EnlistVolatile(vrm2); err != nil {
+			target := NewCommittableTransaction(TransactionOptions{ResponseTimeout: time.Second})
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
 				t.Fatal(err)
 			}
-			if err := target.EnlistTheOnlyDurable(drm); err != nil {
+			if _, err := target.EnlistVolatile(vrm2); err != nil {
 				t.Fatal(err)
 			}
 
-			wg.Add(2)
-			mock.InOrder(
-				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
-					Once(),
-				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
-					Once(),
-				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
-					Once(),
-				vrm1.EXPECT().Commit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-				vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-			)
+			var lateEnl PreparingEnlistment
+			vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { lateEnl = enl }).
+				Once()
+			vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once()
+			vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+			vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+			start := time.Now()
 
 			// Act
 			actErr := target.Commit(t.Context())
 
-			assert_.NoError(actErr)
-			wg.Wait()
+			assert_.Equal(time.Second, time.Since(start))
+			var abortErr *AbortError
+			if assert_.ErrorAs(actErr, &abortErr) {
+				assert_.Equal([]AbortVeto{
+					{Enlistment: 0, Phase: AbortPhasePrepare, Cause: ErrTxResponseTimeout},
+				}, abortErr.Vetoes)
+			}
+			assert_.ErrorIs(actErr, ErrTxResponseTimeout)
+			lateEnl.Prepared()
+			assert_.NoError(target.WaitCompleted(t.Context()))
 		})
+	})
 
-		t.Run("Получая асинхронные ответы", func(t *testing.T) {
+	t.Run("Ограничивает время ожидания ответов на Prepare на весь шаг подготовки", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
 			assert_ := assert.New(t)
-			var wg sync.WaitGroup
 			vrm1 := NewMockEnlistmentNotification(t)
 			vrm2 := NewMockEnlistmentNotification(t)
-			drm := NewMockSinglePhaseNotification(t)
 
-			target := CommittableTransaction{}
-			if err := target.EnlistVolatile(vrm1); err != nil {
-				t.Fatal(err)
-			}
-			if err := target.EnlistVolatile(vrm2); err != nil {
+			target := NewCommittableTransaction(TransactionOptions{ResponseTimeout: time.Second})
+			h, err := target.EnlistVolatile(vrm1)
+			if err != nil {
 				t.Fatal(err)
 			}
-			if err := target.EnlistTheOnlyDurable(drm); err != nil {
+			if _, err := target.EnlistVolatile(vrm2, WithDependsOn(h)); err != nil {
 				t.Fatal(err)
 			}
 
-			wg.Add(2)
+			prepareLate := func(ctx context.Context, enl PreparingEnlistment) {
+				go func() {
+					time.Sleep(600 * time.Millisecond)
+					enl.Prepared()
+				}()
+			}
 			mock.InOrder(
-				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) { go enl.Prepared() }).
-					Once(),
-				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) { go enl.Prepared() }).
-					Once(),
-				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { go enl.Committed() }).
-					Once(),
-				vrm1.EXPECT().Commit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { go func() { defer wg.Done(); enl.Done() }() }).
-					Once(),
-				vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { go func() { defer wg.Done(); enl.Done() }() }).
-					Once(),
+				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).Run(prepareLate).Once(),
+				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).Run(prepareLate).Once(),
 			)
+			vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+			vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+			start := time.Now()
 
 			// Act
 			actErr := target.Commit(t.Context())
 
-			assert_.NoError(actErr)
-			wg.Wait()
+			assert_.Equal(time.Second, time.Since(start))
+			var abortErr *AbortError
+			if assert_.ErrorAs(actErr, &abortErr) {
+				assert_.Equal([]AbortVeto{
+					{Enlistment: 1, Phase: AbortPhasePrepare, Cause: ErrTxResponseTimeout},
+				}, abortErr.Vetoes)
+			}
+			time.Sleep(time.Second)
+			assert_.NoError(target.WaitCompleted(t.Context()))
 		})
 	})
 
-	t.Run("Отменяет все", func(t *testing.T) {
-		t.Run("Получая асинхронную ошибку SPC TOD", func(t *testing.T) {
+	t.Run("Возвращает ErrTxInDoubt, если участник не ответил вовремя на SPC", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
 			assert_ := assert.New(t)
-			var wg sync.WaitGroup
-			vrm1 := NewMockEnlistmentNotification(t)
-			vrm2 := NewMockEnlistmentNotification(t)
+			vrm := NewMockEnlistmentNotification(t)
 			drm := NewMockSinglePhaseNotification(t)
-			theErr := errors.New("#THE_ERR")
 
-			target := CommittableTransaction{}
-			if err := target.EnlistVolatile(vrm1); err != nil {
+			target := NewCommittableTransaction(TransactionOptions{ResponseTimeout: time.Second})
+			if _, err := target.EnlistVolatile(vrm); err != nil {
 				t.Fatal(err)
 			}
-			if err := target.EnlistVolatile(vrm2); err != nil {
-				t.Fatal(err)
-			}
-			if err := target.EnlistTheOnlyDurable(drm); err != nil {
+			if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
 				t.Fatal(err)
 			}
 
-			wg.Add(3)
+			var lateEnl SinglePhaseEnlistment
 			mock.InOrder(
-				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
-					Once(),
-				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+				vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
 					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
 					Once(),
 				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { go enl.Aborted(theErr) }).
+					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { lateEnl = enl }).
 					Once(),
-				drm.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-				vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-				vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				vrm.EXPECT().InDoubt(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
 					Once(),
 			)
 
 			// Act
 			actErr := target.Commit(t.Context())
 
-			assert_.ErrorIs(actErr, ErrTxAborted)
-			wg.Wait()
+			assert_.ErrorIs(actErr, ErrTxInDoubt)
+			assert_.ErrorIs(actErr, ErrTxResponseTimeout)
+			lateEnl.Committed()
+			assert_.NoError(target.WaitCompleted(t.Context()))
 		})
+	})
 
-		t.Run("Получая синхронную ошибку подготовки Volatile", func(t *testing.T) {
+	t.Run("Отменяет изменения при отмененном контексте", func(t *testing.T) {
+		assert_ := assert.New(t)
+		vrm := NewMockEnlistmentNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+			Once()
+
+		// Act
+		actErr := target.Commit(ctx)
+
+		var abortErr *AbortError
+		if assert_.ErrorAs(actErr, &abortErr) {
+			assert_.Equal([]AbortVeto{
+				{Enlistment: -1, Phase: AbortPhaseCanceled, Cause: context.Canceled},
+			}, abortErr.Vetoes)
+		}
+		assert_.ErrorIs(actErr, ErrTxAborted)
+		assert_.ErrorIs(actErr, context.Canceled)
+		assert_.NoError(target.WaitCompleted(t.Context()))
+	})
+
+	t.Run("Отменяет изменения транзакции без участников при отмененном контексте", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		// Act
+		actErr := target.Commit(ctx)
+
+		assert_.ErrorIs(actErr, ErrTxAborted)
+		assert_.ErrorIs(actErr, context.Canceled)
+	})
+
+	t.Run("Отменяет изменения при отмене контекста во время подготовки", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
 			assert_ := assert.New(t)
-			var wg sync.WaitGroup
-			vrm1 := NewMockEnlistmentNotification(t)
-			vrm2 := NewMockEnlistmentNotification(t)
-			drm := NewMockSinglePhaseNotification(t)
 			theErr := errors.New("#THE_ERR")
+			vrm := NewMockEnlistmentNotification(t)
 
-			target := CommittableTransaction{}
-			if err := target.EnlistVolatile(vrm1); err != nil {
-				t.Fatal(err)
-			}
-			if err := target.EnlistVolatile(vrm2); err != nil {
-				t.Fatal(err)
-			}
-			if err := target.EnlistTheOnlyDurable(drm); err != nil {
+			target := NewCommittableTransaction(TransactionOptions{})
+			if _, err := target.EnlistVolatile(vrm); err != nil {
 				t.Fatal(err)
 			}
+			ctx, cancel := context.WithCancelCause(t.Context())
 
-			wg.Add(3)
-			mock.InOrder(
-				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
-					Once(),
-				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.ForceRollback(theErr) }).
-					Once(),
-				drm.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-				vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-				vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-			)
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { cancel(theErr) }).
+				Once()
+			vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
 
 			// Act
-			actErr := target.Commit(t.Context())
+			actErr := target.Commit(ctx)
 
 			assert_.ErrorIs(actErr, ErrTxAborted)
-			wg.Wait()
+			assert_.ErrorIs(actErr, context.Canceled)
+			assert_.ErrorIs(actErr, theErr)
+			assert_.NoError(target.WaitCompleted(t.Context()))
 		})
 	})
 
-	t.Run("Допускает вложенные Rollback на первой фазе", func(t *testing.T) {
-		t.Run("Volatile, синхронно", func(t *testing.T) {
+	t.Run("Возвращает ErrTxInDoubt при отмене контекста во время SPC", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
 			assert_ := assert.New(t)
-			var wg sync.WaitGroup
-			vrm1 := NewMockEnlistmentNotification(t)
-			vrm2 := NewMockEnlistmentNotification(t)
+			vrm := NewMockEnlistmentNotification(t)
 			drm := NewMockSinglePhaseNotification(t)
 
-			target := CommittableTransaction{}
-			if err := target.EnlistVolatile(vrm1); err != nil {
-				t.Fatal(err)
-			}
-			if err := target.EnlistVolatile(vrm2); err != nil {
+			target := NewCommittableTransaction(TransactionOptions{})
+			if _, err := target.EnlistVolatile(vrm); err != nil {
 				t.Fatal(err)
 			}
-			if err := target.EnlistTheOnlyDurable(drm); err != nil {
+			if _, err := target.EnlistTheOnlyDurable(drm); err != nil {
 				t.Fatal(err)
 			}
+			ctx, cancel := context.WithCancel(t.Context())
 
-			var rbErr error
-			wg.Add(3)
 			mock.InOrder(
-				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+				vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
 					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
 					Once(),
-				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) {
-						rbErr = target.Rollback(ctx)
-						enl.Prepared()
-					}).
-					Once(),
-				drm.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-				vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { cancel() }).
 					Once(),
-				vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				vrm.EXPECT().InDoubt(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
 					Once(),
 			)
 
 			// Act
-			actErr := target.Commit(t.Context())
+			actErr := target.Commit(ctx)
 
-			assert_.ErrorIs(actErr, ErrTxAborted)
-			assert_.NoError(rbErr)
-			wg.Wait()
+			assert_.ErrorIs(actErr, ErrTxInDoubt)
+			assert_.ErrorIs(actErr, context.Canceled)
+			assert_.NoError(target.WaitCompleted(t.Context()))
 		})
+	})
 
-		t.Run("Volatile, асинхронно", func(t *testing.T) {
+	t.Run("Отменяет контекст подготовки при отказе участника", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
 			assert_ := assert.New(t)
-			var wg sync.WaitGroup
+			theErr := errors.New("#THE_ERR")
 			vrm1 := NewMockEnlistmentNotification(t)
 			vrm2 := NewMockEnlistmentNotification(t)
-			drm := NewMockSinglePhaseNotification(t)
 
-			target := CommittableTransaction{}
-			if err := target.EnlistVolatile(vrm1); err != nil {
-				t.Fatal(err)
-			}
-			if err := target.EnlistVolatile(vrm2); err != nil {
+			target := NewCommittableTransaction(TransactionOptions{})
+			if _, err := target.EnlistVolatile(vrm1); err != nil {
 				t.Fatal(err)
 			}
-			if err := target.EnlistTheOnlyDurable(drm); err != nil {
+			if _, err := target.EnlistVolatile(vrm2); err != nil {
 				t.Fatal(err)
 			}
 
-			var rbErr error
-			wg.Add(3)
-			mock.InOrder(
-				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
-					Once(),
-				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) {
-						go func() {
-							rbErr = target.Rollback(ctx)
-							enl.Prepared()
-						}()
-					}).
-					Once(),
-				drm.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-				vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-				vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-			)
+			var actCause error
+			vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.ForceRollback(theErr) }).
+				Once()
+			vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) {
+					go func() {
+						<-ctx.Done()
+						actCause = context.Cause(ctx)
+						enl.Prepared()
+					}()
+				}).
+				Once()
+			vrm1.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+			vrm2.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
 
 			// Act
 			actErr := target.Commit(t.Context())
 
-			assert_.ErrorIs(actErr, ErrTxAborted)
-			assert_.NoError(rbErr)
-			wg.Wait()
+			assert_.ErrorIs(actErr, theErr)
+			assert_.ErrorIs(actCause, ErrTxAborted)
+			assert_.ErrorIs(actCause, theErr)
+			assert_.NoError(target.WaitCompleted(t.Context()))
 		})
 	})
 
-	t.Run("Допускает вложенные присоединения на первой фазе", func(t *testing.T) {
-		t.Run("Синхронное присоединение TOD", func(t *testing.T) {
+	t.Run("Отменяет контекст подготовки при вложенном Rollback", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
 			assert_ := assert.New(t)
-			var wg sync.WaitGroup
-			vrm1 := NewMockEnlistmentNotification(t)
-			vrm2 := NewMockEnlistmentNotification(t)
-			drm := NewMockSinglePhaseNotification(t)
+			theErr := errors.New("#THE_ERR")
+			vrm := NewMockEnlistmentNotification(t)
 
-			target := CommittableTransaction{}
-			if err := target.EnlistVolatile(vrm1); err != nil {
-				t.Fatal(err)
-			}
-			if err := target.EnlistVolatile(vrm2); err != nil {
+			target := NewCommittableTransaction(TransactionOptions{})
+			if _, err := target.EnlistVolatile(vrm); err != nil {
 				t.Fatal(err)
 			}
 
-			var enlErr error
-			wg.Add(2)
-			mock.InOrder(
-				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
-					Once(),
-				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) {
-						enlErr = target.EnlistTheOnlyDurable(drm)
+			var actCause error
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) {
+					go func() {
+						<-ctx.Done()
+						actCause = context.Cause(ctx)
 						enl.Prepared()
-					}).
-					Once(),
-				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
-					Once(),
-				vrm1.EXPECT().Commit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-				vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-			)
+					}()
+				}).
+				Once()
+			vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
 
 			// Act
-			actErr := target.Commit(t.Context())
+			res := target.BeginCommit(t.Context())
 
-			assert_.NoError(actErr)
-			assert_.NoError(enlErr)
-			wg.Wait()
+			synctest.Wait()
+			assert_.NoError(target.RollbackErr(t.Context(), theErr))
+			<-res.Done()
+			assert_.ErrorIs(res.Err(), theErr)
+			assert_.ErrorIs(actCause, ErrTxAborted)
+			assert_.ErrorIs(actCause, theErr)
+			assert_.NoError(target.WaitCompleted(t.Context()))
 		})
+	})
 
-		t.Run("Асинхронное присоединение TOD", func(t *testing.T) {
+	t.Run("Передает на последнем этапе не отменяемый контекст", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
 			assert_ := assert.New(t)
-			var wg sync.WaitGroup
-			vrm1 := NewMockEnlistmentNotification(t)
-			vrm2 := NewMockEnlistmentNotification(t)
-			drm := NewMockSinglePhaseNotification(t)
+			type key struct{}
+			vrm := NewMockEnlistmentNotification(t)
 
-			target := CommittableTransaction{}
-			if err := target.EnlistVolatile(vrm1); err != nil {
-				t.Fatal(err)
-			}
-			if err := target.EnlistVolatile(vrm2); err != nil {
+			target := NewCommittableTransaction(TransactionOptions{Phase2Timeout: time.Minute})
+			if _, err := target.EnlistVolatile(vrm); err != nil {
 				t.Fatal(err)
 			}
+			ctx, cancel := context.WithCancel(context.WithValue(t.Context(), key{}, "#THE_VALUE"))
 
-			var enlErr error
-			wg.Add(2)
-			mock.InOrder(
-				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
-					Once(),
-				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) {
-						go func() {
-							enlErr = target.EnlistTheOnlyDurable(drm)
-							enl.Prepared()
-						}()
-					}).
-					Once(),
-				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
-					Once(),
-				vrm1.EXPECT().Commit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-				vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
+			var (
+				p2Ctx context.Context
+				p2Enl Enlistment
 			)
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once()
+			vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { p2Ctx, p2Enl = ctx, enl }).
+				Once()
 
 			// Act
-			actErr := target.Commit(t.Context())
+			actErr := target.Commit(ctx)
 
 			assert_.NoError(actErr)
-			assert_.NoError(enlErr)
-			wg.Wait()
+			cancel()
+			assert_.NoError(p2Ctx.Err())
+			assert_.Equal("#THE_VALUE", p2Ctx.Value(key{}))
+			time.Sleep(time.Minute)
+			synctest.Wait()
+			assert_.ErrorIs(p2Ctx.Err(), context.DeadlineExceeded)
+			p2Enl.Done()
+			assert_.NoError(target.WaitCompleted(t.Context()))
 		})
+	})
 
-		t.Run("Синхронное присоединение Volatile", func(t *testing.T) {
+	t.Run("Выполняет подготовку и фиксацию с учетом зависимостей", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		cache := NewMockEnlistmentNotification(t)
+		aggregator := NewMockEnlistmentNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		h, err := target.EnlistVolatile(cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistVolatile(aggregator, WithPrecedes(h)); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(2)
+		mock.InOrder(
+			aggregator.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once(),
+			cache.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once(),
+			aggregator.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once(),
+			cache.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once(),
+		)
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.NoError(actErr)
+		wg.Wait()
+	})
+
+	t.Run("Выбирает участника SPC заново, если он продвинут вложенно", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		psn := NewMockPromotableSinglePhaseNotification(t)
+		promoted := NewMockEnlistmentNotification(t)
+		vrm := NewMockEnlistmentNotification(t)
+		drm := NewMockEnlistmentNotification(t)
+
+		target := NewCommittableTransaction(TransactionOptions{})
+		h, err := target.EnlistPromotable(psn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := target.EnlistVolatile(vrm, WithDependsOn(h)); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(3)
+		mock.InOrder(
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) {
+					_, err := target.EnlistDurable(drm)
+					assert_.NoError(err)
+					enl.Prepared()
+				}).
+				Once(),
+			psn.EXPECT().Promote().Return(promoted, nil).Once(),
+		)
+		for _, drm := range []*MockEnlistmentNotification{promoted, drm} {
+			drm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once()
+		}
+		for _, trm := range []*MockEnlistmentNotification{promoted, drm, vrm} {
+			trm.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				Once()
+		}
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.NoError(actErr)
+		wg.Wait()
+	})
+
+	t.Run("Уведомляет зависимого участника после ответа диспетчера долговременных ресурсов", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
 			assert_ := assert.New(t)
-			var wg sync.WaitGroup
-			vrm1 := NewMockEnlistmentNotification(t)
-			vrm2 := NewMockEnlistmentNotification(t)
-			drm := NewMockSinglePhaseNotification(t)
+			drm := NewMockEnlistmentNotification(t)
+			cache := NewMockEnlistmentNotification(t)
 
-			target := CommittableTransaction{}
-			if err := target.EnlistVolatile(vrm1); err != nil {
+			target := NewCommittableTransaction(TransactionOptions{})
+			h, err := target.EnlistDurable(drm)
+			if err != nil {
 				t.Fatal(err)
 			}
-			if err := target.EnlistTheOnlyDurable(drm); err != nil {
+			if _, err := target.EnlistVolatile(cache, WithDependsOn(h)); err != nil {
 				t.Fatal(err)
 			}
 
-			var enlErr error
-			wg.Add(2)
+			var drmCommitted atomic.Bool
 			mock.InOrder(
-				vrm1.EXPECT().Prepare(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl PreparingEnlistment) {
-						enlErr = target.EnlistVolatile(vrm2)
-						enl.Prepared()
-					}).
-					Once(),
-				vrm2.EXPECT().Prepare(mock.Anything, mock.Anything).
+				drm.EXPECT().Prepare(mock.Anything, mock.Anything).
 					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
 					Once(),
-				drm.EXPECT().SinglePhaseCommit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl SinglePhaseEnlistment) { enl.Committed() }).
-					Once(),
-				vrm1.EXPECT().Commit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
-					Once(),
-				vrm2.EXPECT().Commit(mock.Anything, mock.Anything).
-					Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+				cache.EXPECT().Prepare(mock.Anything, mock.Anything).
+					Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
 					Once(),
 			)
+			drm.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) {
+					go func() {
+						time.Sleep(time.Second)
+						drmCommitted.Store(true)
+						enl.Done()
+					}()
+				}).
+				Once()
+			cache.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) {
+					assert_.True(drmCommitted.Load())
+					enl.Done()
+				}).
+				Once()
 
 			// Act
 			actErr := target.Commit(t.Context())
 
 			assert_.NoError(actErr)
-			assert_.NoError(enlErr)
-			wg.Wait()
+			assert_.NoError(target.WaitCompleted(t.Context()))
 		})
 	})
+
+	t.Run("Уведомляет участников исполнителем транзакции", func(t *testing.T) {
+		assert_ := assert.New(t)
+		vrm := NewMockEnlistmentNotification(t)
+		executor := NewMockExecutor(t)
+
+		target := NewCommittableTransaction(TransactionOptions{Executor: executor})
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+
+		executor.EXPECT().Execute(mock.Anything).
+			Run(func(f func()) { f() }).
+			Twice()
+		vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+			Once()
+		vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+			Once()
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.NoError(actErr)
+		assert_.NoError(target.WaitCompleted(t.Context()))
+	})
+
+	t.Run("Выполняет подготовку участников конкурентно", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var prepareWg, commitWg sync.WaitGroup
+
+		target := NewCommittableTransaction(TransactionOptions{Executor: GoroutineExecutor()})
+		vrms := []*MockEnlistmentNotification{NewMockEnlistmentNotification(t), NewMockEnlistmentNotification(t)}
+		prepareWg.Add(len(vrms))
+		commitWg.Add(len(vrms))
+		for _, vrm := range vrms {
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) {
+					// Подготовка завершается только после начала подготовки всех участников
+					prepareWg.Done()
+					prepareWg.Wait()
+					enl.Prepared()
+				}).
+				Once()
+			vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { defer commitWg.Done(); enl.Done() }).
+				Once()
+		}
+
+		// Act
+		actErr := target.Commit(t.Context())
+
+		assert_.NoError(actErr)
+		commitWg.Wait()
+	})
 }
@@ -0,0 +1,63 @@
+package qtx
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// DependentTransaction - зависимый клон транзакции, см. [Transaction.DependentClone].
+// Используется для работы в транзакции из других горутин: все методы Transaction, включая Rollback, относятся к
+// исходной транзакции, а завершение работы в клоне отмечается вызовом Complete.
+type DependentTransaction struct {
+	Transaction
+
+	tx        *CommittableTransaction
+	opt       DependentCloneOption
+	tracked   bool // Клон учитывается при фиксации изменений.
+	completed atomic.Bool
+}
+
+// Complete отмечает завершение работы в зависимом клоне. Не фиксирует и не отменяет изменения в транзакции.
+// Может использоваться конкурентно.
+//
+// Возвращает nil если работа отмечена завершенной и ErrInvalidOperation если она уже была отмечена завершенной ранее.
+func (d *DependentTransaction) Complete() error {
+	if !d.completed.CompareAndSwap(false, true) {
+		return ErrInvalidOperation
+	}
+	if d.tracked {
+		d.tx.completeClone(d.opt)
+	}
+	return nil
+}
+
+// causeErr реализует [Cause].
+func (d *DependentTransaction) causeErr() error {
+	return d.tx.causeErr()
+}
+
+// afterDoom реализует [WithCancelOnAbort].
+func (d *DependentTransaction) afterDoom(f func(status TransactionStatus, err error)) (stop func() bool) {
+	return d.tx.afterDoom(f)
+}
+
+// DependentCloneOption определяет поведение фиксации изменений в транзакции при не завершенном зависимом клоне.
+type DependentCloneOption int
+
+const (
+	// DependentCloneBlockCommitUntilComplete - фиксация изменений ожидает завершения клона.
+	DependentCloneBlockCommitUntilComplete DependentCloneOption = iota
+	// DependentCloneRollbackIfNotComplete - фиксация изменений отменяет транзакцию с причиной
+	// ErrTxDependentIncomplete.
+	DependentCloneRollbackIfNotComplete
+)
+
+func (o DependentCloneOption) String() string {
+	switch o {
+	case DependentCloneBlockCommitUntilComplete:
+		return "BLOCK_COMMIT_UNTIL_COMPLETE"
+	case DependentCloneRollbackIfNotComplete:
+		return "ROLLBACK_IF_NOT_COMPLETE"
+	}
+	return fmt.Sprintf("DependentCloneOption(%d)", int(o))
+}
@@ -0,0 +1,224 @@
+package qtx
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"sync"
+	"testing"
+	"testing/synctest"
+)
+
+func TestCommittableTransaction_DependentClone(t *testing.T) {
+	t.Run("Возвращает клон транзакции", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := NewCommittableTransaction(TransactionOptions{Name: "#THE_TX"})
+
+		// Act
+		clone := target.DependentClone(DependentCloneBlockCommitUntilComplete)
+
+		assert_.Equal(target.TransactionInformation(), clone.TransactionInformation())
+		assert_.Equal(target.Options(), clone.Options())
+		assert_.NoError(clone.Complete())
+	})
+
+	t.Run("Commit ожидает завершения блокирующего клона", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			vrm := NewMockEnlistmentNotification(t)
+			target := NewCommittableTransaction(TransactionOptions{})
+			clone := target.DependentClone(DependentCloneBlockCommitUntilComplete)
+
+			committed := false
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				// Act
+				assert_.NoError(target.Commit(t.Context()))
+				committed = true
+			}()
+
+			synctest.Wait()
+			assert_.False(committed)
+			assert_.Equal(TransactionStatusPreparing, clone.TransactionInformation().Status)
+
+			if _, err := clone.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+			vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl PreparingEnlistment) { enl.Prepared() }).
+				Once()
+			vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+			assert_.NoError(clone.Complete())
+
+			wg.Wait()
+			assert_.True(committed)
+		})
+	})
+
+	t.Run("Commit ожидает клоны, созданные во время ожидания", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			target := NewCommittableTransaction(TransactionOptions{})
+			clone1 := target.DependentClone(DependentCloneBlockCommitUntilComplete)
+
+			committed := false
+			go func() {
+				// Act
+				assert_.NoError(target.Commit(t.Context()))
+				committed = true
+			}()
+
+			synctest.Wait()
+			clone2 := clone1.DependentClone(DependentCloneBlockCommitUntilComplete)
+			assert_.NoError(clone1.Complete())
+			synctest.Wait()
+			assert_.False(committed)
+			assert_.NoError(clone2.Complete())
+			synctest.Wait()
+			assert_.True(committed)
+		})
+	})
+
+	t.Run("Rollback клона прерывает ожидание Commit", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			expCause := errors.New("cause")
+			var wg sync.WaitGroup
+			vrm := NewMockEnlistmentNotification(t)
+			target := NewCommittableTransaction(TransactionOptions{})
+			if _, err := target.EnlistVolatile(vrm); err != nil {
+				t.Fatal(err)
+			}
+			clone := target.DependentClone(DependentCloneBlockCommitUntilComplete)
+			vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+				Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+				Once()
+
+			var actErr error
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				// Act
+				actErr = target.Commit(t.Context())
+			}()
+
+			synctest.Wait()
+			assert_.NoError(clone.RollbackErr(t.Context(), expCause))
+
+			wg.Wait()
+			var abortErr *AbortError
+			if assert_.ErrorAs(actErr, &abortErr) {
+				assert_.Equal([]AbortVeto{
+					{Enlistment: -1, Phase: AbortPhaseRollback, Cause: expCause},
+				}, abortErr.Vetoes)
+			}
+			assert_.NoError(clone.Complete())
+		})
+	})
+
+	t.Run("Commit отменяет транзакцию при не завершенном клоне", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm := NewMockEnlistmentNotification(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+		clone := target.DependentClone(DependentCloneRollbackIfNotComplete)
+		wg.Add(1)
+		vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+			Once()
+
+		// Act
+		err := target.Commit(t.Context())
+
+		assert_.ErrorIs(err, ErrTxAborted)
+		assert_.ErrorIs(err, ErrTxDependentIncomplete)
+		assert_.ErrorIs(Cause(target), ErrTxDependentIncomplete)
+		assert_.NoError(clone.Complete())
+		wg.Wait()
+	})
+
+	t.Run("Commit фиксирует изменения при завершенном клоне", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+		clone := target.DependentClone(DependentCloneRollbackIfNotComplete)
+		if err := clone.Complete(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		err := target.Commit(t.Context())
+
+		assert_.NoError(err)
+	})
+
+	t.Run("Не учитывает клоны, созданные после начала фиксации", func(t *testing.T) {
+		assert_ := assert.New(t)
+		var wg sync.WaitGroup
+		vrm := NewMockEnlistmentNotification(t)
+		target := NewCommittableTransaction(TransactionOptions{})
+		if _, err := target.EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) {
+				target.DependentClone(DependentCloneBlockCommitUntilComplete)
+				target.DependentClone(DependentCloneRollbackIfNotComplete)
+				enl.Prepared()
+			}).
+			Once()
+		vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { defer wg.Done(); enl.Done() }).
+			Once()
+
+		// Act
+		err := target.Commit(t.Context())
+
+		assert_.NoError(err)
+		wg.Wait()
+	})
+}
+
+func TestDependentTransaction_Complete(t *testing.T) {
+	t.Run("Не допускает повторное завершение", func(t *testing.T) {
+		assert_ := assert.New(t)
+		target := NewCommittableTransaction(TransactionOptions{}).DependentClone(DependentCloneBlockCommitUntilComplete)
+		if err := target.Complete(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		err := target.Complete()
+
+		assert_.ErrorIs(err, ErrInvalidOperation)
+	})
+}
+
+func TestCause_DependentTransaction(t *testing.T) {
+	t.Run("Возвращает причину отмены исходной транзакции", func(t *testing.T) {
+		assert_ := assert.New(t)
+		expCause := errors.New("cause")
+		tx := NewCommittableTransaction(TransactionOptions{})
+		target := tx.DependentClone(DependentCloneBlockCommitUntilComplete)
+		if err := target.RollbackErr(t.Context(), expCause); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		actCause := Cause(target)
+
+		assert_.ErrorIs(actCause, expCause)
+		assert_.Equal(Cause(tx), actCause)
+		assert_.NoError(target.Complete())
+	})
+}
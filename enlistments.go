@@ -6,6 +6,7 @@ const (
 	trmResponseCodeDone trmResponseCode = iota
 	trmResponseCodeAbort
 	trmResponseCodeCommit
+	trmResponseCodeInDoubt
 )
 
 // ---
@@ -27,6 +28,10 @@ func (en enlistment) Done() {
 	en.resp <- trmResponse{code: trmResponseCodeDone, enlId: en.id}
 }
 
+func (en enlistment) ReadOnly() {
+	en.resp <- trmResponse{code: trmResponseCodeDone, enlId: en.id}
+}
+
 func (en enlistment) ForceRollback(cause error) {
 	en.resp <- trmResponse{code: trmResponseCodeAbort, enlId: en.id, cause: cause}
 }
@@ -42,3 +47,7 @@ func (en enlistment) Aborted(cause error) {
 func (en enlistment) Committed() {
 	en.resp <- trmResponse{code: trmResponseCodeCommit, enlId: en.id}
 }
+
+func (en enlistment) InDoubt(cause error) {
+	en.resp <- trmResponse{code: trmResponseCodeInDoubt, enlId: en.id, cause: cause}
+}
@@ -0,0 +1,52 @@
+package qtx
+
+// Executor выполняет уведомления участников транзакции (Prepare, Commit, Rollback и InDoubt), см.
+// [TransactionOptions.Executor].
+type Executor interface {
+	// Execute выполняет f синхронно или конкурентно. Может использоваться конкурентно.
+	Execute(f func())
+}
+
+// InlineExecutor возвращает исполнитель, выполняющий уведомления синхронно, в вызывающей горутине.
+func InlineExecutor() Executor {
+	return inlineExecutor{}
+}
+
+// GoroutineExecutor возвращает исполнитель, выполняющий каждое уведомление в отдельной горутине.
+func GoroutineExecutor() Executor {
+	return goroutineExecutor{}
+}
+
+// PoolExecutor возвращает исполнитель, выполняющий уведомления конкурентно, не более size одновременно: при
+// исчерпании лимита Execute блокируется до завершения одного из выполняемых уведомлений.
+// Нулевое или отрицательное значение size равнозначно 1.
+// Исполнитель может использоваться несколькими транзакциями, ограничивая количество уведомлений всех этих транзакций.
+func PoolExecutor(size int) Executor {
+	return poolExecutor{sem: make(chan struct{}, max(size, 1))}
+}
+
+// ---
+
+type inlineExecutor struct{}
+
+func (inlineExecutor) Execute(f func()) {
+	f()
+}
+
+type goroutineExecutor struct{}
+
+func (goroutineExecutor) Execute(f func()) {
+	go f()
+}
+
+type poolExecutor struct {
+	sem chan struct{}
+}
+
+func (e poolExecutor) Execute(f func()) {
+	e.sem <- struct{}{}
+	go func() {
+		defer func() { <-e.sem }()
+		f()
+	}()
+}
@@ -0,0 +1,79 @@
+package qtx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+func TestInlineExecutor(t *testing.T) {
+	t.Run("Выполняет функцию синхронно", func(t *testing.T) {
+		assert_ := assert.New(t)
+		executed := false
+
+		// Act
+		InlineExecutor().Execute(func() { executed = true })
+
+		assert_.True(executed)
+	})
+}
+
+func TestGoroutineExecutor(t *testing.T) {
+	t.Run("Выполняет функции конкурентно", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			target := GoroutineExecutor()
+
+			// Act
+			start := time.Now()
+			for range 3 {
+				wg.Add(1)
+				target.Execute(func() { defer wg.Done(); time.Sleep(time.Second) })
+			}
+			wg.Wait()
+
+			assert_.Equal(time.Second, time.Since(start))
+		})
+	})
+}
+
+func TestPoolExecutor(t *testing.T) {
+	t.Run("Выполняет функции конкурентно, не более size одновременно", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			target := PoolExecutor(2)
+
+			// Act
+			start := time.Now()
+			for range 3 {
+				wg.Add(1)
+				target.Execute(func() { defer wg.Done(); time.Sleep(time.Second) })
+			}
+			wg.Wait()
+
+			assert_.Equal(2*time.Second, time.Since(start))
+		})
+	})
+
+	t.Run("Выполняет функции последовательно при нулевом size", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			assert_ := assert.New(t)
+			var wg sync.WaitGroup
+			target := PoolExecutor(0)
+
+			// Act
+			start := time.Now()
+			for range 2 {
+				wg.Add(1)
+				target.Execute(func() { defer wg.Done(); time.Sleep(time.Second) })
+			}
+			wg.Wait()
+
+			assert_.Equal(2*time.Second, time.Since(start))
+		})
+	})
+}
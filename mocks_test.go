@@ -10,6 +10,73 @@ import (
 	mock "github.com/stretchr/testify/mock"
 )
 
+// NewMockExecutor creates a new instance of MockExecutor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockExecutor(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockExecutor {
+	mock := &MockExecutor{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockExecutor is an autogenerated mock type for the Executor type
+type MockExecutor struct {
+	mock.Mock
+}
+
+type MockExecutor_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockExecutor) EXPECT() *MockExecutor_Expecter {
+	return &MockExecutor_Expecter{mock: &_m.Mock}
+}
+
+// Execute provides a mock function for the type MockExecutor
+func (_mock *MockExecutor) Execute(f func()) {
+	_mock.Called(f)
+	return
+}
+
+// MockExecutor_Execute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Execute'
+type MockExecutor_Execute_Call struct {
+	*mock.Call
+}
+
+// Execute is a helper method to define mock.On call
+//   - f func()
+func (_e *MockExecutor_Expecter) Execute(f interface{}) *MockExecutor_Execute_Call {
+	return &MockExecutor_Execute_Call{Call: _e.mock.On("Execute", f)}
+}
+
+func (_c *MockExecutor_Execute_Call) Run(run func(f func())) *MockExecutor_Execute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 func()
+		if args[0] != nil {
+			arg0 = args[0].(func())
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockExecutor_Execute_Call) Return() *MockExecutor_Execute_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockExecutor_Execute_Call) RunAndReturn(run func(f func())) *MockExecutor_Execute_Call {
+	_c.Run(run)
+	return _c
+}
+
 // NewMockEnlistment creates a new instance of MockEnlistment. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockEnlistment(t interface {
@@ -170,6 +237,46 @@ func (_c *MockSinglePhaseEnlistment_Committed_Call) RunAndReturn(run func()) *Mo
 	return _c
 }
 
+// InDoubt provides a mock function for the type MockSinglePhaseEnlistment
+func (_mock *MockSinglePhaseEnlistment) InDoubt(cause error) {
+	_mock.Called(cause)
+	return
+}
+
+// MockSinglePhaseEnlistment_InDoubt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InDoubt'
+type MockSinglePhaseEnlistment_InDoubt_Call struct {
+	*mock.Call
+}
+
+// InDoubt is a helper method to define mock.On call
+//   - cause error
+func (_e *MockSinglePhaseEnlistment_Expecter) InDoubt(cause interface{}) *MockSinglePhaseEnlistment_InDoubt_Call {
+	return &MockSinglePhaseEnlistment_InDoubt_Call{Call: _e.mock.On("InDoubt", cause)}
+}
+
+func (_c *MockSinglePhaseEnlistment_InDoubt_Call) Run(run func(cause error)) *MockSinglePhaseEnlistment_InDoubt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 error
+		if args[0] != nil {
+			arg0 = args[0].(error)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSinglePhaseEnlistment_InDoubt_Call) Return() *MockSinglePhaseEnlistment_InDoubt_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockSinglePhaseEnlistment_InDoubt_Call) RunAndReturn(run func(cause error)) *MockSinglePhaseEnlistment_InDoubt_Call {
+	_c.Run(run)
+	return _c
+}
+
 // NewMockPreparingEnlistment creates a new instance of MockPreparingEnlistment. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockPreparingEnlistment(t interface {
@@ -303,6 +410,39 @@ func (_c *MockPreparingEnlistment_Prepared_Call) RunAndReturn(run func()) *MockP
 	return _c
 }
 
+// ReadOnly provides a mock function for the type MockPreparingEnlistment
+func (_mock *MockPreparingEnlistment) ReadOnly() {
+	_mock.Called()
+	return
+}
+
+// MockPreparingEnlistment_ReadOnly_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReadOnly'
+type MockPreparingEnlistment_ReadOnly_Call struct {
+	*mock.Call
+}
+
+// ReadOnly is a helper method to define mock.On call
+func (_e *MockPreparingEnlistment_Expecter) ReadOnly() *MockPreparingEnlistment_ReadOnly_Call {
+	return &MockPreparingEnlistment_ReadOnly_Call{Call: _e.mock.On("ReadOnly")}
+}
+
+func (_c *MockPreparingEnlistment_ReadOnly_Call) Run(run func()) *MockPreparingEnlistment_ReadOnly_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockPreparingEnlistment_ReadOnly_Call) Return() *MockPreparingEnlistment_ReadOnly_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockPreparingEnlistment_ReadOnly_Call) RunAndReturn(run func()) *MockPreparingEnlistment_ReadOnly_Call {
+	_c.Run(run)
+	return _c
+}
+
 // NewMockEnlistmentNotification creates a new instance of MockEnlistmentNotification. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockEnlistmentNotification(t interface {
@@ -376,6 +516,52 @@ func (_c *MockEnlistmentNotification_Commit_Call) RunAndReturn(run func(ctx cont
 	return _c
 }
 
+// InDoubt provides a mock function for the type MockEnlistmentNotification
+func (_mock *MockEnlistmentNotification) InDoubt(ctx context.Context, enl Enlistment) {
+	_mock.Called(ctx, enl)
+	return
+}
+
+// MockEnlistmentNotification_InDoubt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InDoubt'
+type MockEnlistmentNotification_InDoubt_Call struct {
+	*mock.Call
+}
+
+// InDoubt is a helper method to define mock.On call
+//   - ctx context.Context
+//   - enl Enlistment
+func (_e *MockEnlistmentNotification_Expecter) InDoubt(ctx interface{}, enl interface{}) *MockEnlistmentNotification_InDoubt_Call {
+	return &MockEnlistmentNotification_InDoubt_Call{Call: _e.mock.On("InDoubt", ctx, enl)}
+}
+
+func (_c *MockEnlistmentNotification_InDoubt_Call) Run(run func(ctx context.Context, enl Enlistment)) *MockEnlistmentNotification_InDoubt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 Enlistment
+		if args[1] != nil {
+			arg1 = args[1].(Enlistment)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEnlistmentNotification_InDoubt_Call) Return() *MockEnlistmentNotification_InDoubt_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockEnlistmentNotification_InDoubt_Call) RunAndReturn(run func(ctx context.Context, enl Enlistment)) *MockEnlistmentNotification_InDoubt_Call {
+	_c.Run(run)
+	return _c
+}
+
 // Prepare provides a mock function for the type MockEnlistmentNotification
 func (_mock *MockEnlistmentNotification) Prepare(ctx context.Context, enl PreparingEnlistment) {
 	_mock.Called(ctx, enl)
@@ -541,6 +727,52 @@ func (_c *MockSinglePhaseNotification_Commit_Call) RunAndReturn(run func(ctx con
 	return _c
 }
 
+// InDoubt provides a mock function for the type MockSinglePhaseNotification
+func (_mock *MockSinglePhaseNotification) InDoubt(ctx context.Context, enl Enlistment) {
+	_mock.Called(ctx, enl)
+	return
+}
+
+// MockSinglePhaseNotification_InDoubt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InDoubt'
+type MockSinglePhaseNotification_InDoubt_Call struct {
+	*mock.Call
+}
+
+// InDoubt is a helper method to define mock.On call
+//   - ctx context.Context
+//   - enl Enlistment
+func (_e *MockSinglePhaseNotification_Expecter) InDoubt(ctx interface{}, enl interface{}) *MockSinglePhaseNotification_InDoubt_Call {
+	return &MockSinglePhaseNotification_InDoubt_Call{Call: _e.mock.On("InDoubt", ctx, enl)}
+}
+
+func (_c *MockSinglePhaseNotification_InDoubt_Call) Run(run func(ctx context.Context, enl Enlistment)) *MockSinglePhaseNotification_InDoubt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 Enlistment
+		if args[1] != nil {
+			arg1 = args[1].(Enlistment)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSinglePhaseNotification_InDoubt_Call) Return() *MockSinglePhaseNotification_InDoubt_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockSinglePhaseNotification_InDoubt_Call) RunAndReturn(run func(ctx context.Context, enl Enlistment)) *MockSinglePhaseNotification_InDoubt_Call {
+	_c.Run(run)
+	return _c
+}
+
 // Prepare provides a mock function for the type MockSinglePhaseNotification
 func (_mock *MockSinglePhaseNotification) Prepare(ctx context.Context, enl PreparingEnlistment) {
 	_mock.Called(ctx, enl)
@@ -679,13 +911,13 @@ func (_c *MockSinglePhaseNotification_SinglePhaseCommit_Call) RunAndReturn(run f
 	return _c
 }
 
-// NewMockTransaction creates a new instance of MockTransaction. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewMockReadOnlyNotification creates a new instance of MockReadOnlyNotification. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewMockTransaction(t interface {
+func NewMockReadOnlyNotification(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *MockTransaction {
-	mock := &MockTransaction{}
+}) *MockReadOnlyNotification {
+	mock := &MockReadOnlyNotification{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -693,146 +925,845 @@ func NewMockTransaction(t interface {
 	return mock
 }
 
-// MockTransaction is an autogenerated mock type for the Transaction type
-type MockTransaction struct {
+// MockReadOnlyNotification is an autogenerated mock type for the ReadOnlyNotification type
+type MockReadOnlyNotification struct {
 	mock.Mock
 }
 
-type MockTransaction_Expecter struct {
+type MockReadOnlyNotification_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *MockTransaction) EXPECT() *MockTransaction_Expecter {
-	return &MockTransaction_Expecter{mock: &_m.Mock}
+func (_m *MockReadOnlyNotification) EXPECT() *MockReadOnlyNotification_Expecter {
+	return &MockReadOnlyNotification_Expecter{mock: &_m.Mock}
 }
 
-// EnlistTheOnlyDurable provides a mock function for the type MockTransaction
-func (_mock *MockTransaction) EnlistTheOnlyDurable(trm SinglePhaseNotification) error {
-	ret := _mock.Called(trm)
+// IsReadOnly provides a mock function for the type MockReadOnlyNotification
+func (_mock *MockReadOnlyNotification) IsReadOnly() bool {
+	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for EnlistTheOnlyDurable")
+		panic("no return value specified for IsReadOnly")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(SinglePhaseNotification) error); ok {
-		r0 = returnFunc(trm)
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(bool)
 	}
 	return r0
 }
 
-// MockTransaction_EnlistTheOnlyDurable_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnlistTheOnlyDurable'
-type MockTransaction_EnlistTheOnlyDurable_Call struct {
+// MockReadOnlyNotification_IsReadOnly_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsReadOnly'
+type MockReadOnlyNotification_IsReadOnly_Call struct {
 	*mock.Call
 }
 
-// EnlistTheOnlyDurable is a helper method to define mock.On call
-//   - trm SinglePhaseNotification
-func (_e *MockTransaction_Expecter) EnlistTheOnlyDurable(trm interface{}) *MockTransaction_EnlistTheOnlyDurable_Call {
-	return &MockTransaction_EnlistTheOnlyDurable_Call{Call: _e.mock.On("EnlistTheOnlyDurable", trm)}
+// IsReadOnly is a helper method to define mock.On call
+func (_e *MockReadOnlyNotification_Expecter) IsReadOnly() *MockReadOnlyNotification_IsReadOnly_Call {
+	return &MockReadOnlyNotification_IsReadOnly_Call{Call: _e.mock.On("IsReadOnly")}
 }
 
-func (_c *MockTransaction_EnlistTheOnlyDurable_Call) Run(run func(trm SinglePhaseNotification)) *MockTransaction_EnlistTheOnlyDurable_Call {
+func (_c *MockReadOnlyNotification_IsReadOnly_Call) Run(run func()) *MockReadOnlyNotification_IsReadOnly_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 SinglePhaseNotification
-		if args[0] != nil {
-			arg0 = args[0].(SinglePhaseNotification)
-		}
-		run(
-			arg0,
-		)
+		run()
 	})
 	return _c
 }
 
-func (_c *MockTransaction_EnlistTheOnlyDurable_Call) Return(err error) *MockTransaction_EnlistTheOnlyDurable_Call {
-	_c.Call.Return(err)
+func (_c *MockReadOnlyNotification_IsReadOnly_Call) Return(b bool) *MockReadOnlyNotification_IsReadOnly_Call {
+	_c.Call.Return(b)
 	return _c
 }
 
-func (_c *MockTransaction_EnlistTheOnlyDurable_Call) RunAndReturn(run func(trm SinglePhaseNotification) error) *MockTransaction_EnlistTheOnlyDurable_Call {
+func (_c *MockReadOnlyNotification_IsReadOnly_Call) RunAndReturn(run func() bool) *MockReadOnlyNotification_IsReadOnly_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// EnlistVolatile provides a mock function for the type MockTransaction
-func (_mock *MockTransaction) EnlistVolatile(trm EnlistmentNotification) error {
-	ret := _mock.Called(trm)
+// NewMockPromotableSinglePhaseNotification creates a new instance of MockPromotableSinglePhaseNotification. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPromotableSinglePhaseNotification(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPromotableSinglePhaseNotification {
+	mock := &MockPromotableSinglePhaseNotification{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockPromotableSinglePhaseNotification is an autogenerated mock type for the PromotableSinglePhaseNotification type
+type MockPromotableSinglePhaseNotification struct {
+	mock.Mock
+}
+
+type MockPromotableSinglePhaseNotification_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPromotableSinglePhaseNotification) EXPECT() *MockPromotableSinglePhaseNotification_Expecter {
+	return &MockPromotableSinglePhaseNotification_Expecter{mock: &_m.Mock}
+}
+
+// Promote provides a mock function for the type MockPromotableSinglePhaseNotification
+func (_mock *MockPromotableSinglePhaseNotification) Promote() (EnlistmentNotification, error) {
+	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for EnlistVolatile")
+		panic("no return value specified for Promote")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(EnlistmentNotification) error); ok {
-		r0 = returnFunc(trm)
+	var r0 EnlistmentNotification
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func() (EnlistmentNotification, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() EnlistmentNotification); ok {
+		r0 = returnFunc()
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(EnlistmentNotification)
+		}
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// MockTransaction_EnlistVolatile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnlistVolatile'
-type MockTransaction_EnlistVolatile_Call struct {
+// MockPromotableSinglePhaseNotification_Promote_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Promote'
+type MockPromotableSinglePhaseNotification_Promote_Call struct {
 	*mock.Call
 }
 
-// EnlistVolatile is a helper method to define mock.On call
-//   - trm EnlistmentNotification
-func (_e *MockTransaction_Expecter) EnlistVolatile(trm interface{}) *MockTransaction_EnlistVolatile_Call {
-	return &MockTransaction_EnlistVolatile_Call{Call: _e.mock.On("EnlistVolatile", trm)}
+// Promote is a helper method to define mock.On call
+func (_e *MockPromotableSinglePhaseNotification_Expecter) Promote() *MockPromotableSinglePhaseNotification_Promote_Call {
+	return &MockPromotableSinglePhaseNotification_Promote_Call{Call: _e.mock.On("Promote")}
 }
 
-func (_c *MockTransaction_EnlistVolatile_Call) Run(run func(trm EnlistmentNotification)) *MockTransaction_EnlistVolatile_Call {
+func (_c *MockPromotableSinglePhaseNotification_Promote_Call) Run(run func()) *MockPromotableSinglePhaseNotification_Promote_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 EnlistmentNotification
-		if args[0] != nil {
-			arg0 = args[0].(EnlistmentNotification)
-		}
-		run(
-			arg0,
-		)
+		run()
 	})
 	return _c
 }
 
-func (_c *MockTransaction_EnlistVolatile_Call) Return(err error) *MockTransaction_EnlistVolatile_Call {
-	_c.Call.Return(err)
+func (_c *MockPromotableSinglePhaseNotification_Promote_Call) Return(enlistmentNotification EnlistmentNotification, err error) *MockPromotableSinglePhaseNotification_Promote_Call {
+	_c.Call.Return(enlistmentNotification, err)
 	return _c
 }
 
-func (_c *MockTransaction_EnlistVolatile_Call) RunAndReturn(run func(trm EnlistmentNotification) error) *MockTransaction_EnlistVolatile_Call {
+func (_c *MockPromotableSinglePhaseNotification_Promote_Call) RunAndReturn(run func() (EnlistmentNotification, error)) *MockPromotableSinglePhaseNotification_Promote_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Rollback provides a mock function for the type MockTransaction
-func (_mock *MockTransaction) Rollback(context1 context.Context) error {
-	ret := _mock.Called(context1)
-
-	if len(ret) == 0 {
-		panic("no return value specified for Rollback")
-	}
-
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context) error); ok {
-		r0 = returnFunc(context1)
-	} else {
-		r0 = ret.Error(0)
-	}
-	return r0
+// Rollback provides a mock function for the type MockPromotableSinglePhaseNotification
+func (_mock *MockPromotableSinglePhaseNotification) Rollback(ctx context.Context, enl Enlistment) {
+	_mock.Called(ctx, enl)
+	return
 }
 
-// MockTransaction_Rollback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Rollback'
-type MockTransaction_Rollback_Call struct {
+// MockPromotableSinglePhaseNotification_Rollback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Rollback'
+type MockPromotableSinglePhaseNotification_Rollback_Call struct {
 	*mock.Call
 }
 
 // Rollback is a helper method to define mock.On call
-//   - context1 context.Context
-func (_e *MockTransaction_Expecter) Rollback(context1 interface{}) *MockTransaction_Rollback_Call {
+//   - ctx context.Context
+//   - enl Enlistment
+func (_e *MockPromotableSinglePhaseNotification_Expecter) Rollback(ctx interface{}, enl interface{}) *MockPromotableSinglePhaseNotification_Rollback_Call {
+	return &MockPromotableSinglePhaseNotification_Rollback_Call{Call: _e.mock.On("Rollback", ctx, enl)}
+}
+
+func (_c *MockPromotableSinglePhaseNotification_Rollback_Call) Run(run func(ctx context.Context, enl Enlistment)) *MockPromotableSinglePhaseNotification_Rollback_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 Enlistment
+		if args[1] != nil {
+			arg1 = args[1].(Enlistment)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPromotableSinglePhaseNotification_Rollback_Call) Return() *MockPromotableSinglePhaseNotification_Rollback_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockPromotableSinglePhaseNotification_Rollback_Call) RunAndReturn(run func(ctx context.Context, enl Enlistment)) *MockPromotableSinglePhaseNotification_Rollback_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SinglePhaseCommit provides a mock function for the type MockPromotableSinglePhaseNotification
+func (_mock *MockPromotableSinglePhaseNotification) SinglePhaseCommit(ctx context.Context, enl SinglePhaseEnlistment) {
+	_mock.Called(ctx, enl)
+	return
+}
+
+// MockPromotableSinglePhaseNotification_SinglePhaseCommit_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SinglePhaseCommit'
+type MockPromotableSinglePhaseNotification_SinglePhaseCommit_Call struct {
+	*mock.Call
+}
+
+// SinglePhaseCommit is a helper method to define mock.On call
+//   - ctx context.Context
+//   - enl SinglePhaseEnlistment
+func (_e *MockPromotableSinglePhaseNotification_Expecter) SinglePhaseCommit(ctx interface{}, enl interface{}) *MockPromotableSinglePhaseNotification_SinglePhaseCommit_Call {
+	return &MockPromotableSinglePhaseNotification_SinglePhaseCommit_Call{Call: _e.mock.On("SinglePhaseCommit", ctx, enl)}
+}
+
+func (_c *MockPromotableSinglePhaseNotification_SinglePhaseCommit_Call) Run(run func(ctx context.Context, enl SinglePhaseEnlistment)) *MockPromotableSinglePhaseNotification_SinglePhaseCommit_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 SinglePhaseEnlistment
+		if args[1] != nil {
+			arg1 = args[1].(SinglePhaseEnlistment)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPromotableSinglePhaseNotification_SinglePhaseCommit_Call) Return() *MockPromotableSinglePhaseNotification_SinglePhaseCommit_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockPromotableSinglePhaseNotification_SinglePhaseCommit_Call) RunAndReturn(run func(ctx context.Context, enl SinglePhaseEnlistment)) *MockPromotableSinglePhaseNotification_SinglePhaseCommit_Call {
+	_c.Run(run)
+	return _c
+}
+
+// NewMockTransaction creates a new instance of MockTransaction. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTransaction(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTransaction {
+	mock := &MockTransaction{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTransaction is an autogenerated mock type for the Transaction type
+type MockTransaction struct {
+	mock.Mock
+}
+
+type MockTransaction_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTransaction) EXPECT() *MockTransaction_Expecter {
+	return &MockTransaction_Expecter{mock: &_m.Mock}
+}
+
+// AfterFunc provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) AfterFunc(f func(status TransactionStatus, err error)) func() bool {
+	ret := _mock.Called(f)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AfterFunc")
+	}
+
+	var r0 func() bool
+	if returnFunc, ok := ret.Get(0).(func(func(status TransactionStatus, err error)) func() bool); ok {
+		r0 = returnFunc(f)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(func() bool)
+		}
+	}
+	return r0
+}
+
+// MockTransaction_AfterFunc_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AfterFunc'
+type MockTransaction_AfterFunc_Call struct {
+	*mock.Call
+}
+
+// AfterFunc is a helper method to define mock.On call
+//   - f func(status TransactionStatus, err error)
+func (_e *MockTransaction_Expecter) AfterFunc(f interface{}) *MockTransaction_AfterFunc_Call {
+	return &MockTransaction_AfterFunc_Call{Call: _e.mock.On("AfterFunc", f)}
+}
+
+func (_c *MockTransaction_AfterFunc_Call) Run(run func(f func(status TransactionStatus, err error))) *MockTransaction_AfterFunc_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 func(status TransactionStatus, err error)
+		if args[0] != nil {
+			arg0 = args[0].(func(status TransactionStatus, err error))
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransaction_AfterFunc_Call) Return(stop func() bool) *MockTransaction_AfterFunc_Call {
+	_c.Call.Return(stop)
+	return _c
+}
+
+func (_c *MockTransaction_AfterFunc_Call) RunAndReturn(run func(f func(status TransactionStatus, err error)) func() bool) *MockTransaction_AfterFunc_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DependentClone provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) DependentClone(opt DependentCloneOption) *DependentTransaction {
+	ret := _mock.Called(opt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DependentClone")
+	}
+
+	var r0 *DependentTransaction
+	if returnFunc, ok := ret.Get(0).(func(DependentCloneOption) *DependentTransaction); ok {
+		r0 = returnFunc(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DependentTransaction)
+		}
+	}
+	return r0
+}
+
+// MockTransaction_DependentClone_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DependentClone'
+type MockTransaction_DependentClone_Call struct {
+	*mock.Call
+}
+
+// DependentClone is a helper method to define mock.On call
+//   - opt DependentCloneOption
+func (_e *MockTransaction_Expecter) DependentClone(opt interface{}) *MockTransaction_DependentClone_Call {
+	return &MockTransaction_DependentClone_Call{Call: _e.mock.On("DependentClone", opt)}
+}
+
+func (_c *MockTransaction_DependentClone_Call) Run(run func(opt DependentCloneOption)) *MockTransaction_DependentClone_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 DependentCloneOption
+		if args[0] != nil {
+			arg0 = args[0].(DependentCloneOption)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransaction_DependentClone_Call) Return(dependentTransaction *DependentTransaction) *MockTransaction_DependentClone_Call {
+	_c.Call.Return(dependentTransaction)
+	return _c
+}
+
+func (_c *MockTransaction_DependentClone_Call) RunAndReturn(run func(opt DependentCloneOption) *DependentTransaction) *MockTransaction_DependentClone_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Done provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) Done() <-chan struct{} {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Done")
+	}
+
+	var r0 <-chan struct{}
+	if returnFunc, ok := ret.Get(0).(func() <-chan struct{}); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan struct{})
+		}
+	}
+	return r0
+}
+
+// MockTransaction_Done_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Done'
+type MockTransaction_Done_Call struct {
+	*mock.Call
+}
+
+// Done is a helper method to define mock.On call
+func (_e *MockTransaction_Expecter) Done() *MockTransaction_Done_Call {
+	return &MockTransaction_Done_Call{Call: _e.mock.On("Done")}
+}
+
+func (_c *MockTransaction_Done_Call) Run(run func()) *MockTransaction_Done_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockTransaction_Done_Call) Return(valCh <-chan struct{}) *MockTransaction_Done_Call {
+	_c.Call.Return(valCh)
+	return _c
+}
+
+func (_c *MockTransaction_Done_Call) RunAndReturn(run func() <-chan struct{}) *MockTransaction_Done_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnlistDurable provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) EnlistDurable(trm EnlistmentNotification, opts ...EnlistOption) (*EnlistmentHandle, error) {
+	var tmpRet mock.Arguments
+	if len(opts) > 0 {
+		tmpRet = _mock.Called(trm, opts)
+	} else {
+		tmpRet = _mock.Called(trm)
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnlistDurable")
+	}
+
+	var r0 *EnlistmentHandle
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(EnlistmentNotification, ...EnlistOption) (*EnlistmentHandle, error)); ok {
+		return returnFunc(trm, opts...)
+	}
+	if returnFunc, ok := ret.Get(0).(func(EnlistmentNotification, ...EnlistOption) *EnlistmentHandle); ok {
+		r0 = returnFunc(trm, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*EnlistmentHandle)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(EnlistmentNotification, ...EnlistOption) error); ok {
+		r1 = returnFunc(trm, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTransaction_EnlistDurable_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnlistDurable'
+type MockTransaction_EnlistDurable_Call struct {
+	*mock.Call
+}
+
+// EnlistDurable is a helper method to define mock.On call
+//   - trm EnlistmentNotification
+//   - opts ...EnlistOption
+func (_e *MockTransaction_Expecter) EnlistDurable(trm interface{}, opts ...interface{}) *MockTransaction_EnlistDurable_Call {
+	return &MockTransaction_EnlistDurable_Call{Call: _e.mock.On("EnlistDurable",
+		append([]interface{}{trm}, opts...)...)}
+}
+
+func (_c *MockTransaction_EnlistDurable_Call) Run(run func(trm EnlistmentNotification, opts ...EnlistOption)) *MockTransaction_EnlistDurable_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 EnlistmentNotification
+		if args[0] != nil {
+			arg0 = args[0].(EnlistmentNotification)
+		}
+		var arg1 []EnlistOption
+		variadicArgs := make([]EnlistOption, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(EnlistOption)
+			}
+		}
+		arg1 = variadicArgs
+		run(
+			arg0,
+			arg1...,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransaction_EnlistDurable_Call) Return(enlistmentHandle *EnlistmentHandle, err error) *MockTransaction_EnlistDurable_Call {
+	_c.Call.Return(enlistmentHandle, err)
+	return _c
+}
+
+func (_c *MockTransaction_EnlistDurable_Call) RunAndReturn(run func(trm EnlistmentNotification, opts ...EnlistOption) (*EnlistmentHandle, error)) *MockTransaction_EnlistDurable_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnlistPromotable provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) EnlistPromotable(trm PromotableSinglePhaseNotification, opts ...EnlistOption) (*EnlistmentHandle, error) {
+	var tmpRet mock.Arguments
+	if len(opts) > 0 {
+		tmpRet = _mock.Called(trm, opts)
+	} else {
+		tmpRet = _mock.Called(trm)
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnlistPromotable")
+	}
+
+	var r0 *EnlistmentHandle
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(PromotableSinglePhaseNotification, ...EnlistOption) (*EnlistmentHandle, error)); ok {
+		return returnFunc(trm, opts...)
+	}
+	if returnFunc, ok := ret.Get(0).(func(PromotableSinglePhaseNotification, ...EnlistOption) *EnlistmentHandle); ok {
+		r0 = returnFunc(trm, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*EnlistmentHandle)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(PromotableSinglePhaseNotification, ...EnlistOption) error); ok {
+		r1 = returnFunc(trm, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTransaction_EnlistPromotable_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnlistPromotable'
+type MockTransaction_EnlistPromotable_Call struct {
+	*mock.Call
+}
+
+// EnlistPromotable is a helper method to define mock.On call
+//   - trm PromotableSinglePhaseNotification
+//   - opts ...EnlistOption
+func (_e *MockTransaction_Expecter) EnlistPromotable(trm interface{}, opts ...interface{}) *MockTransaction_EnlistPromotable_Call {
+	return &MockTransaction_EnlistPromotable_Call{Call: _e.mock.On("EnlistPromotable",
+		append([]interface{}{trm}, opts...)...)}
+}
+
+func (_c *MockTransaction_EnlistPromotable_Call) Run(run func(trm PromotableSinglePhaseNotification, opts ...EnlistOption)) *MockTransaction_EnlistPromotable_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 PromotableSinglePhaseNotification
+		if args[0] != nil {
+			arg0 = args[0].(PromotableSinglePhaseNotification)
+		}
+		var arg1 []EnlistOption
+		variadicArgs := make([]EnlistOption, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(EnlistOption)
+			}
+		}
+		arg1 = variadicArgs
+		run(
+			arg0,
+			arg1...,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransaction_EnlistPromotable_Call) Return(enlistmentHandle *EnlistmentHandle, err error) *MockTransaction_EnlistPromotable_Call {
+	_c.Call.Return(enlistmentHandle, err)
+	return _c
+}
+
+func (_c *MockTransaction_EnlistPromotable_Call) RunAndReturn(run func(trm PromotableSinglePhaseNotification, opts ...EnlistOption) (*EnlistmentHandle, error)) *MockTransaction_EnlistPromotable_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnlistTheOnlyDurable provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) EnlistTheOnlyDurable(trm SinglePhaseNotification, opts ...EnlistOption) (*EnlistmentHandle, error) {
+	var tmpRet mock.Arguments
+	if len(opts) > 0 {
+		tmpRet = _mock.Called(trm, opts)
+	} else {
+		tmpRet = _mock.Called(trm)
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnlistTheOnlyDurable")
+	}
+
+	var r0 *EnlistmentHandle
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(SinglePhaseNotification, ...EnlistOption) (*EnlistmentHandle, error)); ok {
+		return returnFunc(trm, opts...)
+	}
+	if returnFunc, ok := ret.Get(0).(func(SinglePhaseNotification, ...EnlistOption) *EnlistmentHandle); ok {
+		r0 = returnFunc(trm, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*EnlistmentHandle)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(SinglePhaseNotification, ...EnlistOption) error); ok {
+		r1 = returnFunc(trm, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTransaction_EnlistTheOnlyDurable_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnlistTheOnlyDurable'
+type MockTransaction_EnlistTheOnlyDurable_Call struct {
+	*mock.Call
+}
+
+// EnlistTheOnlyDurable is a helper method to define mock.On call
+//   - trm SinglePhaseNotification
+//   - opts ...EnlistOption
+func (_e *MockTransaction_Expecter) EnlistTheOnlyDurable(trm interface{}, opts ...interface{}) *MockTransaction_EnlistTheOnlyDurable_Call {
+	return &MockTransaction_EnlistTheOnlyDurable_Call{Call: _e.mock.On("EnlistTheOnlyDurable",
+		append([]interface{}{trm}, opts...)...)}
+}
+
+func (_c *MockTransaction_EnlistTheOnlyDurable_Call) Run(run func(trm SinglePhaseNotification, opts ...EnlistOption)) *MockTransaction_EnlistTheOnlyDurable_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 SinglePhaseNotification
+		if args[0] != nil {
+			arg0 = args[0].(SinglePhaseNotification)
+		}
+		var arg1 []EnlistOption
+		variadicArgs := make([]EnlistOption, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(EnlistOption)
+			}
+		}
+		arg1 = variadicArgs
+		run(
+			arg0,
+			arg1...,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransaction_EnlistTheOnlyDurable_Call) Return(enlistmentHandle *EnlistmentHandle, err error) *MockTransaction_EnlistTheOnlyDurable_Call {
+	_c.Call.Return(enlistmentHandle, err)
+	return _c
+}
+
+func (_c *MockTransaction_EnlistTheOnlyDurable_Call) RunAndReturn(run func(trm SinglePhaseNotification, opts ...EnlistOption) (*EnlistmentHandle, error)) *MockTransaction_EnlistTheOnlyDurable_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnlistVolatile provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) EnlistVolatile(trm EnlistmentNotification, opts ...EnlistOption) (*EnlistmentHandle, error) {
+	var tmpRet mock.Arguments
+	if len(opts) > 0 {
+		tmpRet = _mock.Called(trm, opts)
+	} else {
+		tmpRet = _mock.Called(trm)
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnlistVolatile")
+	}
+
+	var r0 *EnlistmentHandle
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(EnlistmentNotification, ...EnlistOption) (*EnlistmentHandle, error)); ok {
+		return returnFunc(trm, opts...)
+	}
+	if returnFunc, ok := ret.Get(0).(func(EnlistmentNotification, ...EnlistOption) *EnlistmentHandle); ok {
+		r0 = returnFunc(trm, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*EnlistmentHandle)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(EnlistmentNotification, ...EnlistOption) error); ok {
+		r1 = returnFunc(trm, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTransaction_EnlistVolatile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnlistVolatile'
+type MockTransaction_EnlistVolatile_Call struct {
+	*mock.Call
+}
+
+// EnlistVolatile is a helper method to define mock.On call
+//   - trm EnlistmentNotification
+//   - opts ...EnlistOption
+func (_e *MockTransaction_Expecter) EnlistVolatile(trm interface{}, opts ...interface{}) *MockTransaction_EnlistVolatile_Call {
+	return &MockTransaction_EnlistVolatile_Call{Call: _e.mock.On("EnlistVolatile",
+		append([]interface{}{trm}, opts...)...)}
+}
+
+func (_c *MockTransaction_EnlistVolatile_Call) Run(run func(trm EnlistmentNotification, opts ...EnlistOption)) *MockTransaction_EnlistVolatile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 EnlistmentNotification
+		if args[0] != nil {
+			arg0 = args[0].(EnlistmentNotification)
+		}
+		var arg1 []EnlistOption
+		variadicArgs := make([]EnlistOption, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(EnlistOption)
+			}
+		}
+		arg1 = variadicArgs
+		run(
+			arg0,
+			arg1...,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransaction_EnlistVolatile_Call) Return(enlistmentHandle *EnlistmentHandle, err error) *MockTransaction_EnlistVolatile_Call {
+	_c.Call.Return(enlistmentHandle, err)
+	return _c
+}
+
+func (_c *MockTransaction_EnlistVolatile_Call) RunAndReturn(run func(trm EnlistmentNotification, opts ...EnlistOption) (*EnlistmentHandle, error)) *MockTransaction_EnlistVolatile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Err provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) Err() error {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Err")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockTransaction_Err_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Err'
+type MockTransaction_Err_Call struct {
+	*mock.Call
+}
+
+// Err is a helper method to define mock.On call
+func (_e *MockTransaction_Expecter) Err() *MockTransaction_Err_Call {
+	return &MockTransaction_Err_Call{Call: _e.mock.On("Err")}
+}
+
+func (_c *MockTransaction_Err_Call) Run(run func()) *MockTransaction_Err_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockTransaction_Err_Call) Return(err error) *MockTransaction_Err_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockTransaction_Err_Call) RunAndReturn(run func() error) *MockTransaction_Err_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Options provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) Options() TransactionOptions {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Options")
+	}
+
+	var r0 TransactionOptions
+	if returnFunc, ok := ret.Get(0).(func() TransactionOptions); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(TransactionOptions)
+	}
+	return r0
+}
+
+// MockTransaction_Options_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Options'
+type MockTransaction_Options_Call struct {
+	*mock.Call
+}
+
+// Options is a helper method to define mock.On call
+func (_e *MockTransaction_Expecter) Options() *MockTransaction_Options_Call {
+	return &MockTransaction_Options_Call{Call: _e.mock.On("Options")}
+}
+
+func (_c *MockTransaction_Options_Call) Run(run func()) *MockTransaction_Options_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockTransaction_Options_Call) Return(transactionOptions TransactionOptions) *MockTransaction_Options_Call {
+	_c.Call.Return(transactionOptions)
+	return _c
+}
+
+func (_c *MockTransaction_Options_Call) RunAndReturn(run func() TransactionOptions) *MockTransaction_Options_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Rollback provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) Rollback(context1 context.Context) error {
+	ret := _mock.Called(context1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rollback")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = returnFunc(context1)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockTransaction_Rollback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Rollback'
+type MockTransaction_Rollback_Call struct {
+	*mock.Call
+}
+
+// Rollback is a helper method to define mock.On call
+//   - context1 context.Context
+func (_e *MockTransaction_Expecter) Rollback(context1 interface{}) *MockTransaction_Rollback_Call {
 	return &MockTransaction_Rollback_Call{Call: _e.mock.On("Rollback", context1)}
 }
 
@@ -858,3 +1789,155 @@ func (_c *MockTransaction_Rollback_Call) RunAndReturn(run func(context1 context.
 	_c.Call.Return(run)
 	return _c
 }
+
+// RollbackErr provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) RollbackErr(ctx context.Context, cause error) error {
+	ret := _mock.Called(ctx, cause)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RollbackErr")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, error) error); ok {
+		r0 = returnFunc(ctx, cause)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockTransaction_RollbackErr_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RollbackErr'
+type MockTransaction_RollbackErr_Call struct {
+	*mock.Call
+}
+
+// RollbackErr is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cause error
+func (_e *MockTransaction_Expecter) RollbackErr(ctx interface{}, cause interface{}) *MockTransaction_RollbackErr_Call {
+	return &MockTransaction_RollbackErr_Call{Call: _e.mock.On("RollbackErr", ctx, cause)}
+}
+
+func (_c *MockTransaction_RollbackErr_Call) Run(run func(ctx context.Context, cause error)) *MockTransaction_RollbackErr_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 error
+		if args[1] != nil {
+			arg1 = args[1].(error)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransaction_RollbackErr_Call) Return(err error) *MockTransaction_RollbackErr_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockTransaction_RollbackErr_Call) RunAndReturn(run func(ctx context.Context, cause error) error) *MockTransaction_RollbackErr_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetRollbackOnly provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) SetRollbackOnly(cause error) error {
+	ret := _mock.Called(cause)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRollbackOnly")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(error) error); ok {
+		r0 = returnFunc(cause)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockTransaction_SetRollbackOnly_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRollbackOnly'
+type MockTransaction_SetRollbackOnly_Call struct {
+	*mock.Call
+}
+
+// SetRollbackOnly is a helper method to define mock.On call
+//   - cause error
+func (_e *MockTransaction_Expecter) SetRollbackOnly(cause interface{}) *MockTransaction_SetRollbackOnly_Call {
+	return &MockTransaction_SetRollbackOnly_Call{Call: _e.mock.On("SetRollbackOnly", cause)}
+}
+
+func (_c *MockTransaction_SetRollbackOnly_Call) Run(run func(cause error)) *MockTransaction_SetRollbackOnly_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 error
+		if args[0] != nil {
+			arg0 = args[0].(error)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransaction_SetRollbackOnly_Call) Return(err error) *MockTransaction_SetRollbackOnly_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockTransaction_SetRollbackOnly_Call) RunAndReturn(run func(cause error) error) *MockTransaction_SetRollbackOnly_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TransactionInformation provides a mock function for the type MockTransaction
+func (_mock *MockTransaction) TransactionInformation() TransactionInformation {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for TransactionInformation")
+	}
+
+	var r0 TransactionInformation
+	if returnFunc, ok := ret.Get(0).(func() TransactionInformation); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(TransactionInformation)
+	}
+	return r0
+}
+
+// MockTransaction_TransactionInformation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TransactionInformation'
+type MockTransaction_TransactionInformation_Call struct {
+	*mock.Call
+}
+
+// TransactionInformation is a helper method to define mock.On call
+func (_e *MockTransaction_Expecter) TransactionInformation() *MockTransaction_TransactionInformation_Call {
+	return &MockTransaction_TransactionInformation_Call{Call: _e.mock.On("TransactionInformation")}
+}
+
+func (_c *MockTransaction_TransactionInformation_Call) Run(run func()) *MockTransaction_TransactionInformation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockTransaction_TransactionInformation_Call) Return(transactionInformation TransactionInformation) *MockTransaction_TransactionInformation_Call {
+	_c.Call.Return(transactionInformation)
+	return _c
+}
+
+func (_c *MockTransaction_TransactionInformation_Call) RunAndReturn(run func() TransactionInformation) *MockTransaction_TransactionInformation_Call {
+	_c.Call.Return(run)
+	return _c
+}
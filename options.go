@@ -0,0 +1,127 @@
+package qtx
+
+import (
+	"fmt"
+	"time"
+)
+
+// TransactionOptions - параметры транзакции.
+type TransactionOptions struct {
+	// Timeout - время жизни транзакции, по истечении которого она отменяется с причиной ErrTxTimeout. Нулевое значение
+	// отключает ограничение.
+	Timeout time.Duration
+
+	// IsolationLevel - уровень изоляции транзакции. Транзакция его не обеспечивает, а лишь сообщает участникам.
+	IsolationLevel IsolationLevel
+
+	// Name и Labels - имя и метки транзакции, используемые в диагностических целях.
+	Name   string
+	Labels map[string]string
+
+	// MaxParticipants - наибольшее количество участников транзакции. Нулевое значение отключает ограничение.
+	MaxParticipants int
+
+	// ResponseTimeout - наибольшее время ожидания ответов участников на каждом шаге фиксации изменений. Не ответивший
+	// вовремя участник подготовки 2PC считается отказавшим с причиной ErrTxResponseTimeout, а не ответивший вовремя
+	// участник SPC - не определившим результат. Нулевое значение отключает ограничение.
+	ResponseTimeout time.Duration
+
+	// Phase2Timeout - время жизни контекста, передаваемого участникам на заключительном этапе Commit и Rollback
+	// (уведомления Commit, Rollback и InDoubt). Этот контекст сохраняет значения контекста Commit или Rollback, но не
+	// отменяется вместе с ним. Нулевое значение отключает ограничение.
+	Phase2Timeout time.Duration
+
+	// Executor - исполнитель уведомлений участников. Позволяет, например, выполнять подготовку 2PC участников
+	// конкурентно - см. [GoroutineExecutor] и [PoolExecutor]. Зависимости между участниками (см. [WithDependsOn]) при
+	// этом соблюдаются. Нулевое значение равнозначно [InlineExecutor].
+	Executor Executor
+}
+
+// compatibleWith проверяет, что транзакция с параметрами ambient может использоваться там, где требуется транзакция с
+// параметрами opts.
+//
+// Возвращает nil если может и ErrTxOptionsMismatch если не может.
+func (opts TransactionOptions) compatibleWith(ambient TransactionOptions) error {
+	if opts.IsolationLevel != IsolationLevelUnspecified && opts.IsolationLevel != ambient.IsolationLevel {
+		return fmt.Errorf("%w: isolation level %v, required %v", ErrTxOptionsMismatch, ambient.IsolationLevel,
+			opts.IsolationLevel)
+	}
+	return nil
+}
+
+// ---
+
+// EnlistOption - опция присоединения диспетчера к транзакции.
+type EnlistOption func(*enlistOptions)
+
+// WithPhase0 присоединяет диспетчер не долговременных ресурсов к фазе 0: все такие диспетчеры завершают подготовку
+// 2PC до ее начала для всех остальных участников. Во время подготовки они могут присоединять к транзакции новых
+// участников, в т.ч. новые диспетчеры фазы 0.
+// Диспетчер фазы 0 может зависеть (см. [WithDependsOn]) только от диспетчеров фазы 0.
+func WithPhase0() EnlistOption {
+	return func(options *enlistOptions) { options.phase0 = true }
+}
+
+// WithDependsOn присоединяет участника, зависящего от участников handles: на каждом этапе фиксации изменений он
+// уведомляется только после получения ответов от них.
+// На фазе подготовки 2PC диспетчер не долговременных ресурсов, зависящий (в т.ч. косвенно) от диспетчера
+// долговременных ресурсов, готовится вместе с диспетчерами долговременных ресурсов, после него. Участник SPC в
+// подготовке не участвует, поэтому зависимости от него на ней не учитываются: SPC всегда выполняется после подготовки
+// всех остальных участников.
+// Если зависимости образуют цикл, то присоединение возвращает ErrTxDependencyCycle.
+func WithDependsOn(handles ...*EnlistmentHandle) EnlistOption {
+	return func(options *enlistOptions) { options.dependsOn = append(options.dependsOn, handles...) }
+}
+
+// WithPrecedes присоединяет участника, от которого зависят участники handles - см. [WithDependsOn].
+// При вложенном присоединении на фазе подготовки 2PC участники handles не должны были уже получить Prepare, иначе
+// присоединение возвращает ErrInvalidOperation.
+func WithPrecedes(handles ...*EnlistmentHandle) EnlistOption {
+	return func(options *enlistOptions) { options.precedes = append(options.precedes, handles...) }
+}
+
+type enlistOptions struct {
+	phase0    bool
+	dependsOn []*EnlistmentHandle
+	precedes  []*EnlistmentHandle
+}
+
+func newEnlistOptions(opts []EnlistOption) enlistOptions {
+	options := enlistOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// ---
+
+// IsolationLevel - уровень изоляции транзакции.
+type IsolationLevel int
+
+const (
+	IsolationLevelUnspecified IsolationLevel = iota
+	IsolationLevelReadUncommitted
+	IsolationLevelReadCommitted
+	IsolationLevelRepeatableRead
+	IsolationLevelSnapshot
+	IsolationLevelSerializable
+)
+
+func (l IsolationLevel) String() string {
+	switch l {
+	case IsolationLevelUnspecified:
+		return "UNSPECIFIED"
+	case IsolationLevelReadUncommitted:
+		return "READ_UNCOMMITTED"
+	case IsolationLevelReadCommitted:
+		return "READ_COMMITTED"
+	case IsolationLevelRepeatableRead:
+		return "REPEATABLE_READ"
+	case IsolationLevelSnapshot:
+		return "SNAPSHOT"
+	case IsolationLevelSerializable:
+		return "SERIALIZABLE"
+	}
+	return fmt.Sprintf("IsolationLevel(%d)", int(l))
+}
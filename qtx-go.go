@@ -3,12 +3,86 @@ package qtx
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
-	ErrTxError          = errors.New("#TX_ILLEGAL_STATE")
-	ErrTxAborted        = fmt.Errorf("#TX_ABORTED: %w", ErrTxError)
-	ErrInvalidOperation = errors.New("#TX_INVALID_OPERATION")
+	ErrTxError               = errors.New("#TX_ILLEGAL_STATE")
+	ErrTxAborted             = fmt.Errorf("#TX_ABORTED: %w", ErrTxError)
+	ErrTxTimeout             = fmt.Errorf("#TX_TIMEOUT: %w", ErrTxAborted)
+	ErrTxDependentIncomplete = fmt.Errorf("#TX_DEPENDENT_INCOMPLETE: %w", ErrTxAborted)
+	ErrTxResponseTimeout     = fmt.Errorf("#TX_RESPONSE_TIMEOUT: %w", ErrTxError)
+	ErrTxInDoubt             = fmt.Errorf("#TX_IN_DOUBT: %w", ErrTxError)
+	ErrTxPromotion           = fmt.Errorf("#TX_PROMOTION_FAILED: %w", ErrTxError)
+	ErrTxTooManyParticipants = fmt.Errorf("#TX_TOO_MANY_PARTICIPANTS: %w", ErrTxError)
+	ErrInvalidOperation      = errors.New("#TX_INVALID_OPERATION")
+	ErrScopeNotCompleted     = errors.New("#TX_SCOPE_NOT_COMPLETED")
+	ErrTxOptionsMismatch     = fmt.Errorf("#TX_OPTIONS_MISMATCH: %w", ErrInvalidOperation)
+	ErrTxDependencyCycle     = fmt.Errorf("#TX_DEPENDENCY_CYCLE: %w", ErrInvalidOperation)
 )
 
+// AbortError - ошибка отмены транзакции в [CommittableTransaction.Commit], содержащая отказы участников.
+// Соответствует ErrTxAborted, а также, по аналогии с errors.Join, причине каждого из отказов.
+type AbortError struct {
+	Vetoes []AbortVeto
+}
+
+func (e *AbortError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrTxAborted.Error())
+	for _, veto := range e.Vetoes {
+		b.WriteString("\n")
+		b.WriteString(veto.String())
+	}
+	return b.String()
+}
+
+func (e *AbortError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Vetoes)+1)
+	errs = append(errs, ErrTxAborted)
+	for _, veto := range e.Vetoes {
+		if veto.Cause != nil {
+			errs = append(errs, veto.Cause)
+		}
+	}
+	return errs
+}
+
+// AbortVeto - отказ от фиксации изменений в транзакции.
+type AbortVeto struct {
+	Enlistment int        // Порядковый номер присоединения участника, либо -1 если отказ получен не от участника.
+	Phase      AbortPhase // Этап, на котором получен отказ.
+	Cause      error      // Причина отказа, если указана.
+}
+
+func (v AbortVeto) String() string {
+	return fmt.Sprintf("#%v %v: %v", v.Enlistment, v.Phase, v.Cause)
+}
+
+// AbortPhase - этап фиксации изменений, на котором получен отказ.
+type AbortPhase int
+
+const (
+	AbortPhasePrepare           AbortPhase = iota // Отказ участника на фазе подготовки 2PC.
+	AbortPhaseSinglePhaseCommit                   // Отказ участника SPC.
+	AbortPhaseRollback                            // Вложенный Rollback на фазе подготовки 2PC.
+	AbortPhaseCanceled                            // Отмена контекста Commit до фиксации SPC.
+)
+
+func (p AbortPhase) String() string {
+	switch p {
+	case AbortPhasePrepare:
+		return "PREPARE"
+	case AbortPhaseSinglePhaseCommit:
+		return "SPC"
+	case AbortPhaseRollback:
+		return "ROLLBACK"
+	case AbortPhaseCanceled:
+		return "CANCELED"
+	}
+	return fmt.Sprintf("AbortPhase(%d)", int(p))
+}
+
+// ---
+
 type contextKey[T any] struct{}
@@ -24,28 +24,41 @@ func WithTransactionScope(ctx context.Context, opts ...ScopeOption) (
 }
 
 func createTransactionScope(ctx context.Context, options *scopeOptions) (context.Context, func() error, func() error) {
-	scope := transactionScope{tx: options.tx}
+	scope := transactionScope{tx: options.tx, rollbackOnly: options.rollbackOnly}
 	ctx = WithTransaction(ctx, scope.tx)
+	scope.ctx = ctx
 	return ctx, scope.complete, scope.dispose
 }
 
 func createRequiresScope(ctx context.Context, options *scopeOptions) (context.Context, func() error, func() error) {
 	if tx := CurrentTransaction(ctx); tx != nil {
-		scope := transactionScope{tx: tx}
+		scope := transactionScope{ctx: ctx, tx: tx, rollbackOnly: options.rollbackOnly}
+		if options.txOptions != nil {
+			scope.err = options.txOptions.compatibleWith(tx.Options())
+		}
 		return ctx, scope.complete, scope.dispose
 	}
 
-	scope := committableScope{}
-	ctx = WithTransaction(ctx, &scope.tx)
+	scope := committableScope{tx: newScopeTransaction(options)}
+	ctx = WithTransaction(ctx, scope.tx)
+	scope.ctx = ctx
 	return ctx, scope.complete, scope.dispose
 }
 
 func createRequiresNewScope(ctx context.Context, options *scopeOptions) (context.Context, func() error, func() error) {
-	scope := committableScope{}
-	ctx = WithTransaction(ctx, &scope.tx)
+	scope := committableScope{tx: newScopeTransaction(options)}
+	ctx = WithTransaction(ctx, scope.tx)
+	scope.ctx = ctx
 	return ctx, scope.complete, scope.dispose
 }
 
+func newScopeTransaction(options *scopeOptions) *CommittableTransaction {
+	if options.txOptions == nil {
+		return NewCommittableTransaction(TransactionOptions{})
+	}
+	return NewCommittableTransaction(*options.txOptions)
+}
+
 func createSuppressScope(ctx context.Context, options *scopeOptions) (context.Context, func() error, func() error) {
 	scope := emptyScope{}
 	ctx = WithTransaction(ctx, nil)
@@ -55,7 +68,8 @@ func createSuppressScope(ctx context.Context, options *scopeOptions) (context.Co
 // ---
 
 type committableScope struct {
-	tx         CommittableTransaction
+	ctx        context.Context // Контекст зоны, передается в Commit и Rollback.
+	tx         *CommittableTransaction
 	terminated bool
 }
 
@@ -63,7 +77,7 @@ func (s *committableScope) dispose() error {
 	if s.terminated {
 		return nil
 	}
-	err := s.tx.Rollback(context.Background())
+	err := s.tx.RollbackErr(s.ctx, ErrScopeNotCompleted)
 	s.terminated = true
 	return err
 }
@@ -72,7 +86,7 @@ func (s *committableScope) complete() error {
 	if s.terminated {
 		return ErrInvalidOperation
 	}
-	err := s.tx.Commit(context.Background())
+	err := s.tx.Commit(s.ctx)
 	s.terminated = true
 	return err
 }
@@ -80,15 +94,27 @@ func (s *committableScope) complete() error {
 // ---
 
 type transactionScope struct {
-	tx         Transaction
-	terminated bool
+	ctx          context.Context // Контекст зоны, передается в Rollback.
+	tx           Transaction
+	err          error // Ошибка создания зоны - если есть, то зона не может быть завершена успешно.
+	rollbackOnly bool  // Не завершенная зона помечает транзакцию как подлежащую отмене вместо ее отмены.
+	terminated   bool
 }
 
 func (s *transactionScope) dispose() error {
 	if s.terminated {
 		return nil
 	}
-	err := s.tx.Rollback(context.Background())
+	cause := ErrScopeNotCompleted
+	if s.err != nil {
+		cause = s.err
+	}
+	var err error
+	if s.rollbackOnly {
+		err = s.tx.SetRollbackOnly(cause)
+	} else {
+		err = s.tx.RollbackErr(s.ctx, cause)
+	}
 	s.terminated = true
 	return err
 }
@@ -97,6 +123,9 @@ func (s *transactionScope) complete() error {
 	if s.terminated {
 		return ErrInvalidOperation
 	}
+	if s.err != nil {
+		return s.err
+	}
 	s.terminated = true
 	return nil
 }
@@ -142,12 +171,28 @@ func WithRequiresNewTx() ScopeOption {
 	return func(options *scopeOptions) { options.createScope = createRequiresNewScope }
 }
 
+// WithTxOptions задает параметры транзакции, создаваемой зоной.
+// Если зона использует текущую транзакцию, то ее параметры должны быть совместимы с opts, иначе зона не может быть
+// завершена успешно: complete возвращает ErrTxOptionsMismatch, а dispose отменяет текущую транзакцию.
+func WithTxOptions(opts TransactionOptions) ScopeOption {
+	return func(options *scopeOptions) { options.txOptions = &opts }
+}
+
+// WithRollbackOnly изменяет поведение dispose не завершенной зоны, использующей текущую или указанную транзакцию:
+// вместо немедленной отмены транзакции она помечается как подлежащая отмене (см. [Transaction.SetRollbackOnly]), а
+// отмена выполняется владельцем транзакции.
+func WithRollbackOnly() ScopeOption {
+	return func(options *scopeOptions) { options.rollbackOnly = true }
+}
+
 // WithSuppressTx создает зону без транзакции.
 func WithSuppressTx() ScopeOption {
 	return func(options *scopeOptions) { options.createScope = createSuppressScope }
 }
 
 type scopeOptions struct {
-	tx          Transaction
-	createScope func(context.Context, *scopeOptions) (context.Context, func() error, func() error)
+	tx           Transaction
+	txOptions    *TransactionOptions
+	rollbackOnly bool
+	createScope  func(context.Context, *scopeOptions) (context.Context, func() error, func() error)
 }
@@ -0,0 +1,120 @@
+package qtx
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestWithTxOptions(t *testing.T) {
+	t.Run("Создает транзакцию с указанными параметрами", func(t *testing.T) {
+		assert_ := assert.New(t)
+		opts := TransactionOptions{IsolationLevel: IsolationLevelSerializable, Name: "#THE_TX"}
+
+		// Act
+		ctx, _, dispose := WithTransactionScope(t.Context(), WithTxOptions(opts))
+		defer func() { _ = dispose() }()
+
+		actTx := CurrentTransaction(ctx)
+		if assert_.NotNil(actTx) {
+			assert_.Equal(opts, actTx.Options())
+		}
+	})
+
+	t.Run("Допускает совместимую текущую транзакцию", func(t *testing.T) {
+		assert_ := assert.New(t)
+		opts := TransactionOptions{IsolationLevel: IsolationLevelSerializable}
+		outerCtx, _, outerDispose := WithTransactionScope(t.Context(), WithTxOptions(opts))
+		defer func() { _ = outerDispose() }()
+
+		// Act
+		ctx, complete, dispose := WithTransactionScope(outerCtx, WithTxRequired(), WithTxOptions(opts))
+
+		assert_.Same(CurrentTransaction(outerCtx), CurrentTransaction(ctx))
+		assert_.NoError(complete())
+		assert_.NoError(dispose())
+		assert_.NoError(Cause(CurrentTransaction(outerCtx)))
+	})
+
+	t.Run("Не допускает несовместимую текущую транзакцию", func(t *testing.T) {
+		assert_ := assert.New(t)
+		outerCtx, _, outerDispose := WithTransactionScope(t.Context(),
+			WithTxOptions(TransactionOptions{IsolationLevel: IsolationLevelReadCommitted}))
+		defer func() { _ = outerDispose() }()
+
+		// Act
+		_, complete, dispose := WithTransactionScope(outerCtx,
+			WithTxOptions(TransactionOptions{IsolationLevel: IsolationLevelSerializable}))
+
+		assert_.ErrorIs(complete(), ErrTxOptionsMismatch)
+		assert_.NoError(dispose())
+		assert_.ErrorIs(Cause(CurrentTransaction(outerCtx)), ErrTxOptionsMismatch)
+	})
+
+	t.Run("Создает новую транзакцию независимо от текущей", func(t *testing.T) {
+		assert_ := assert.New(t)
+		outerCtx, _, outerDispose := WithTransactionScope(t.Context(),
+			WithTxOptions(TransactionOptions{IsolationLevel: IsolationLevelReadCommitted}))
+		defer func() { _ = outerDispose() }()
+
+		// Act
+		ctx, complete, _ := WithTransactionScope(outerCtx, WithRequiresNewTx(),
+			WithTxOptions(TransactionOptions{IsolationLevel: IsolationLevelSerializable}))
+
+		assert_.NotSame(CurrentTransaction(outerCtx), CurrentTransaction(ctx))
+		assert_.Equal(IsolationLevelSerializable, CurrentTransaction(ctx).Options().IsolationLevel)
+		assert_.NoError(complete())
+	})
+}
+
+func TestWithTransactionScope(t *testing.T) {
+	t.Run("Передает контекст зоны участникам", func(t *testing.T) {
+		assert_ := assert.New(t)
+		type key struct{}
+		vrm := NewMockEnlistmentNotification(t)
+		ctx, complete, dispose := WithTransactionScope(context.WithValue(t.Context(), key{}, "#THE_VALUE"))
+		defer func() { _ = dispose() }()
+		if _, err := CurrentTransaction(ctx).EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+
+		var prepValue, commitValue any
+		vrm.EXPECT().Prepare(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl PreparingEnlistment) { prepValue = ctx.Value(key{}); enl.Prepared() }).
+			Once()
+		vrm.EXPECT().Commit(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { commitValue = ctx.Value(key{}); enl.Done() }).
+			Once()
+
+		// Act
+		err := complete()
+
+		assert_.NoError(err)
+		assert_.Equal("#THE_VALUE", prepValue)
+		assert_.Equal("#THE_VALUE", commitValue)
+	})
+}
+
+func TestWithRollbackOnly(t *testing.T) {
+	t.Run("Откладывает отмену текущей транзакции", func(t *testing.T) {
+		assert_ := assert.New(t)
+		vrm := NewMockEnlistmentNotification(t)
+		outerCtx, outerComplete, outerDispose := WithTransactionScope(t.Context())
+		defer func() { _ = outerDispose() }()
+		if _, err := CurrentTransaction(outerCtx).EnlistVolatile(vrm); err != nil {
+			t.Fatal(err)
+		}
+		_, _, dispose := WithTransactionScope(outerCtx, WithRollbackOnly())
+
+		// Act
+		err := dispose()
+
+		assert_.NoError(err)
+		assert_.ErrorIs(Cause(CurrentTransaction(outerCtx)), ErrScopeNotCompleted)
+		vrm.EXPECT().Rollback(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, enl Enlistment) { enl.Done() }).
+			Once()
+		assert_.ErrorIs(outerComplete(), ErrTxAborted)
+	})
+}
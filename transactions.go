@@ -1,6 +1,10 @@
 package qtx
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 type Enlistment interface {
 	// Done indicates that the transaction participant has completed its work.
@@ -10,7 +14,8 @@ type Enlistment interface {
 type SinglePhaseEnlistment interface {
 	Aborted(cause error)
 	Committed()
-	//	InDoubt(cause error)
+	// InDoubt indicates that the outcome of the transaction cannot be determined.
+	InDoubt(cause error)
 }
 
 type PreparingEnlistment interface {
@@ -19,13 +24,21 @@ type PreparingEnlistment interface {
 	ForceRollback(cause error)
 	// Prepared indicates that the transaction can be commited.
 	Prepared()
+	// ReadOnly indicates that the participant has made no changes and votes read-only: the transaction can be
+	// commited, and the participant receives no phase 2 notification (Commit, Rollback or InDoubt).
+	// Calling Done during the prepare phase is equivalent to ReadOnly.
+	ReadOnly()
 }
 
 type EnlistmentNotification interface {
+	// Prepare is called during the prepare phase. The ctx is cancelled as soon as the transaction is doomed to abort,
+	// and the cause of the abort is available via context.Cause(ctx).
 	Prepare(ctx context.Context, enl PreparingEnlistment)
 	Commit(ctx context.Context, enl Enlistment)
+	// Rollback is called when the transaction is aborted. The cause of the abort is available via [AbortCause](ctx).
 	Rollback(ctx context.Context, enl Enlistment)
-	//	InDoubt(enl Enlistment)
+	// InDoubt is called instead of Commit or Rollback when the outcome of the transaction cannot be determined.
+	InDoubt(ctx context.Context, enl Enlistment)
 }
 
 type SinglePhaseNotification interface {
@@ -33,6 +46,30 @@ type SinglePhaseNotification interface {
 	SinglePhaseCommit(ctx context.Context, enl SinglePhaseEnlistment)
 }
 
+// ReadOnlyNotification - необязательный интерфейс участника, с которым взаимодействие производится по протоколу SPC
+// (в т.ч. [PromotableSinglePhaseNotification] до продвижения). Позволяет участнику, не изменявшему ресурсы,
+// проголосовать "только чтение" и не получать SinglePhaseCommit.
+type ReadOnlyNotification interface {
+	// IsReadOnly возвращает true если участник не изменял ресурсы. Вызывается при фиксации изменений перед
+	// SinglePhaseCommit; если возвращает true, то ни SinglePhaseCommit, ни другие уведомления участник не получает.
+	// Как и SinglePhaseCommit, вызывается без блокировки транзакции и может обращаться к ней.
+	IsReadOnly() bool
+}
+
+// PromotableSinglePhaseNotification - диспетчер долгосрочных ресурсов, взаимодействие с которым производится по
+// протоколу SPC до тех пор, пока он остается единственным диспетчером долгосрочных ресурсов транзакции.
+type PromotableSinglePhaseNotification interface {
+	SinglePhaseCommit(ctx context.Context, enl SinglePhaseEnlistment)
+	Rollback(ctx context.Context, enl Enlistment)
+
+	// Promote продвигает диспетчер до полноценного участника 2PC и возвращает диспетчер, который замещает его в
+	// транзакции. После успешного продвижения транзакция к продвинутому диспетчеру больше не обращается.
+	// Вызывается без блокировки транзакции и может к ней обращаться, за исключением Commit, Rollback,
+	// [EnlistmentHandle.Unenlist] и присоединения диспетчеров долгосрочных ресурсов: они ожидают завершения
+	// продвижения.
+	Promote() (EnlistmentNotification, error)
+}
+
 // Transaction - локальная транзакция с множественными участниками-диспетчерами долговременных (durable) и не
 // долговременных (volatile) ресурсов, взаимодействие с которыми производится по протоколам Two Phase Commit (2PC) и
 // Single Phase Commit (SPC).
@@ -41,27 +78,161 @@ type Transaction interface {
 	// EnlistTheOnlyDurable присоединяет диспетчер долгосрочных ресурсов в режиме один-и-только-один. В этом режиме
 	// присоединение других диспетчеров долгосрочных ресурсов не допускается, а взаимодействие с присоединенным
 	// диспетчером всегда производится только по протоколу SPC.
+	// Опции opts задают зависимости между участниками - см. [WithDependsOn] и [WithPrecedes].
 	// Может использоваться конкурентно. На фазе подготовки 2PC также может использоваться вложенно.
 	//
-	// Возвращает nil если диспетчер был присоединен и ErrTxError если статус транзакции не допускает новые
-	// присоединения или если присоединенный диспетчер долговременных ресурсов уже есть.
-	EnlistTheOnlyDurable(trm SinglePhaseNotification) error
+	// Возвращает присоединение, позволяющее отсоединить диспетчер (см. [EnlistmentHandle.Unenlist]), и nil если
+	// диспетчер был присоединен, ErrTxError если статус транзакции не допускает новые присоединения или если
+	// присоединенный диспетчер долговременных ресурсов уже есть, ErrInvalidOperation если опции недопустимы, и
+	// ErrTxDependencyCycle если зависимости образуют цикл.
+	EnlistTheOnlyDurable(trm SinglePhaseNotification, opts ...EnlistOption) (*EnlistmentHandle, error)
 
-	// EnlistVolatile присоединяет диспетчер не долговременных ресурсов.
+	// EnlistDurable присоединяет диспетчер долгосрочных ресурсов, взаимодействие с которым производится по протоколу
+	// 2PC. В этом режиме допускается присоединение нескольких диспетчеров долгосрочных ресурсов. Если хотя бы один из
+	// них реализует [SinglePhaseNotification], то к последнему такому диспетчеру применяется оптимизация последнего
+	// ресурса: взаимодействие с ним производится по протоколу SPC после подготовки всех остальных участников.
+	// Опции opts задают зависимости между участниками - см. [WithDependsOn] и [WithPrecedes].
 	// Может использоваться конкурентно. На фазе подготовки 2PC также может использоваться вложенно.
 	//
-	// Возвращает nil если диспетчер был присоединен и ErrTxError если статус транзакции не допускает новые
-	// присоединения.
-	EnlistVolatile(trm EnlistmentNotification) error
+	// Если есть диспетчер, присоединенный в режиме с продвижением, то он продвигается.
+	//
+	// Возвращает присоединение и nil если диспетчер был присоединен, ErrTxPromotion если продвижение не удалось,
+	// ErrTxError если статус транзакции не допускает новые присоединения или если есть диспетчер долгосрочных ресурсов,
+	// присоединенный в режиме один-и-только-один, ErrInvalidOperation если опции недопустимы, и ErrTxDependencyCycle
+	// если зависимости образуют цикл.
+	EnlistDurable(trm EnlistmentNotification, opts ...EnlistOption) (*EnlistmentHandle, error)
+
+	// EnlistPromotable присоединяет диспетчер долгосрочных ресурсов в режиме с продвижением. Пока присоединенный
+	// диспетчер остается единственным диспетчером долгосрочных ресурсов, взаимодействие с ним производится только по
+	// протоколу SPC. При появлении других диспетчеров долгосрочных ресурсов он продвигается до полноценного участника
+	// 2PC - см. [PromotableSinglePhaseNotification.Promote].
+	// Опции opts задают зависимости между участниками - см. [WithDependsOn] и [WithPrecedes].
+	// Может использоваться конкурентно. На фазе подготовки 2PC также может использоваться вложенно.
+	//
+	// Возвращает присоединение и nil если диспетчер был присоединен, ErrTxPromotion если продвижение не удалось,
+	// ErrTxError если статус транзакции не допускает новые присоединения или если есть диспетчер долгосрочных ресурсов,
+	// присоединенный в режиме один-и-только-один, ErrInvalidOperation если опции недопустимы, и ErrTxDependencyCycle
+	// если зависимости образуют цикл.
+	EnlistPromotable(trm PromotableSinglePhaseNotification, opts ...EnlistOption) (*EnlistmentHandle, error)
+
+	// EnlistVolatile присоединяет диспетчер не долговременных ресурсов с опциями opts - см. [WithPhase0],
+	// [WithDependsOn] и [WithPrecedes].
+	// Может использоваться конкурентно. На фазе подготовки 2PC также может использоваться вложенно.
+	//
+	// Возвращает присоединение и nil если диспетчер был присоединен, ErrTxError если статус транзакции не допускает
+	// новые присоединения, в т.ч. присоединения к фазе 0 после ее завершения, ErrInvalidOperation если опции
+	// недопустимы, и ErrTxDependencyCycle если зависимости образуют цикл.
+	EnlistVolatile(trm EnlistmentNotification, opts ...EnlistOption) (*EnlistmentHandle, error)
 
 	// Rollback отменяет все изменения в транзакции.
+	// Участники получают контекст, который сохраняет значения контекста Rollback, но не отменяется вместе с ним - см.
+	// TransactionOptions.Phase2Timeout.
 	// Блокируется на все время выполнения отмены изменений за исключением заключительной обработки ответов - она
 	// всегда выполняется конкурентно и может завершиться уже после завершения вызова Rollback.
 	// Может использоваться конкурентно. На фазе подготовки 2PC также может использоваться вложенно.
 	//
-	// Возвращает nil если изменения отменены, ErrTxAborted если изменения были отменены ранее, и ErrTxError если
-	// изменения были зафиксированы ранее.
+	// Возвращает nil если изменения отменены, ErrTxAborted если изменения были отменены ранее, ErrTxInDoubt если
+	// результат транзакции не мог быть определен, и ErrTxError если изменения были зафиксированы ранее.
 	Rollback(context.Context) error
 
-	//	RollbackErr(error) error
+	// RollbackErr отменяет все изменения в транзакции аналогично Rollback, и запоминает причину отмены cause, если
+	// транзакция еще не завершена и причина отмены еще не запомнена. Запомненная причина возвращается последующими
+	// Commit и Rollback в дополнение к ErrTxAborted, а также функцией [Cause].
+	RollbackErr(ctx context.Context, cause error) error
+
+	// SetRollbackOnly помечает транзакцию как подлежащую отмене и запоминает причину отмены cause аналогично
+	// RollbackErr, но не выполняет отмену: последующие присоединения не допускаются, а отмена изменений и уведомление
+	// участников выполняются последующим Commit, который возвращает ErrTxAborted, или Rollback. На фазе подготовки 2PC
+	// равнозначен вложенному Rollback.
+	// Транзакция считается завершенной (см. Done, Err и AfterFunc) лишь после отмены изменений, но контекст,
+	// полученный от [WithCancelOnAbort], отменяется сразу.
+	// Может использоваться конкурентно. На фазе подготовки 2PC также может использоваться вложенно.
+	//
+	// Возвращает nil если транзакция помечена как подлежащая отмене или уже отменена, ErrTxInDoubt если результат
+	// транзакции не мог быть определен, и ErrTxError если изменения зафиксированы или фиксируются.
+	SetRollbackOnly(cause error) error
+
+	// Options возвращает параметры транзакции.
+	Options() TransactionOptions
+
+	// TransactionInformation возвращает сведения о транзакции.
+	// Может использоваться конкурентно.
+	TransactionInformation() TransactionInformation
+
+	// AfterFunc регистрирует функцию f, которая будет запущена в отдельной горутине после завершения транзакции, по
+	// аналогии с context.AfterFunc. Функция f получает результирующий статус транзакции - TransactionStatusCommitted,
+	// TransactionStatusAborted или TransactionStatusInDoubt, и ошибку результата: nil если изменения зафиксированы,
+	// [*AbortError] если изменения отменены в Commit, ErrTxAborted с причиной отмены если изменения отменены иначе, и
+	// ErrTxInDoubt с причиной если результат не может быть определен. Если транзакция уже завершена, то f запускается
+	// немедленно.
+	// Может использоваться конкурентно. На фазе подготовки 2PC также может использоваться вложенно.
+	//
+	// Возвращает функцию stop, отменяющую регистрацию f. Функция stop возвращает true если регистрация отменена, и
+	// false если функция f уже запущена или регистрация была отменена ранее.
+	AfterFunc(f func(status TransactionStatus, err error)) (stop func() bool)
+
+	// Done возвращает канал, который закрывается после завершения транзакции, по аналогии с context.Context.Done.
+	// Может использоваться конкурентно.
+	Done() <-chan struct{}
+
+	// Err возвращает nil если транзакция еще не завершена, и ошибку результата, которую получают функции,
+	// зарегистрированные AfterFunc, если завершена: nil если изменения зафиксированы, и ошибку, соответствующую
+	// ErrTxAborted или ErrTxInDoubt, в остальных случаях.
+	// Может использоваться конкурентно.
+	Err() error
+
+	// DependentClone создает зависимый клон транзакции для работы в ней из других горутин. Фиксация изменений в
+	// транзакции не завершается, пока не завершен зависимый клон - см. [DependentCloneOption].
+	// Клон, созданный после начала фиксации изменений, на нее не влияет, за исключением клонов, созданных во время
+	// ожидания завершения клонов с опцией DependentCloneBlockCommitUntilComplete.
+	// Может использоваться конкурентно.
+	DependentClone(opt DependentCloneOption) *DependentTransaction
+}
+
+// TransactionInformation - сведения о транзакции.
+type TransactionInformation struct {
+	LocalID             uint64            // Уникальный в пределах процесса идентификатор транзакции.
+	Status              TransactionStatus // Текущий статус транзакции.
+	CreationTime        time.Time         // Время создания транзакции.
+	DurableEnlistments  int               // Количество присоединений диспетчеров долгосрочных ресурсов.
+	VolatileEnlistments int               // Количество присоединений диспетчеров не долговременных ресурсов.
+	ReadOnlyEnlistments int               // Количество участников, проголосовавших "только чтение".
+}
+
+// TransactionStatus - статус транзакции.
+type TransactionStatus int
+
+const (
+	TransactionStatusActive    TransactionStatus = iota // Транзакция допускает присоединения и работу участников.
+	TransactionStatusPreparing                          // Выполняется фиксация изменений.
+	TransactionStatusCommitted                          // Изменения зафиксированы.
+	TransactionStatusAborted                            // Изменения отменены или будут отменены.
+	TransactionStatusInDoubt                            // Результат транзакции не может быть определен.
+)
+
+func (s TransactionStatus) String() string {
+	switch s {
+	case TransactionStatusActive:
+		return "ACTIVE"
+	case TransactionStatusPreparing:
+		return "PREPARING"
+	case TransactionStatusCommitted:
+		return "COMMITTED"
+	case TransactionStatusAborted:
+		return "ABORTED"
+	case TransactionStatusInDoubt:
+		return "IN_DOUBT"
+	}
+	return fmt.Sprintf("TransactionStatus(%d)", int(s))
+}
+
+// Cause возвращает причину отмены транзакции tx: nil если транзакция не отменена, запомненную причину отмены если
+// она есть, и ErrTxAborted в остальных случаях.
+// Причиной отмены является cause первого вызова RollbackErr, либо причина, указанная участником транзакции, выполнившим
+// ее отмену.
+func Cause(tx Transaction) error {
+	if c, ok := tx.(interface{ causeErr() error }); ok {
+		return c.causeErr()
+	}
+	return nil
 }